@@ -0,0 +1,281 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	colorType    = reflect.TypeOf(Color{})
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+)
+
+// Unmarshal matches line against format and binds the captured vars into
+// dst as Input.Bind does. A *MatchError is returned after binding whatever
+// could be captured.
+func Unmarshal(format, line string, dst any) (err error) {
+	p, err := Compile(format)
+	if err != nil {
+		return
+	}
+	i, mErr := p.MatchString(line)
+	if err = i.Bind(dst); err == nil {
+		err = mErr
+	}
+	return
+}
+
+// Bind copies the captured vars into the struct dst points to. A field is
+// filled from the var named by its `input:"name"` tag, or by its own name
+// when it has no tag; `input:"-"` skips it. Values are converted to the
+// field type: numbers between int, uint and float sizes (with overflow
+// checks), slices and maps element by element, maps into nested structs,
+// and time.Duration from a string ParseDuration reads, such as `2 days`,
+// or a number of seconds. Vars that were not captured leave their field
+// untouched.
+func (i *Input) Bind(dst any) (err error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || !isStruct(rv.Type()) {
+		err = errors.New("input: Bind needs a non-nil pointer to a struct")
+		return
+	}
+	vals := make(map[string]any, len(i.vars))
+	for n, v := range i.vars {
+		if v.Value != nil {
+			vals[n] = v.Value
+		}
+	}
+	err = bindStruct(rv.Elem(), vals)
+	return
+}
+
+func bindStruct(dst reflect.Value, vals map[string]any) (err error) {
+	typ := dst.Type()
+	for x := 0; x < typ.NumField(); x++ {
+		f := typ.Field(x)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("input"); ok {
+			if name = strings.Split(tag, ",")[0]; name == "-" {
+				continue
+			}
+		}
+		val, ok := vals[name]
+		if !ok {
+			continue
+		}
+		if err = assign(dst.Field(x), val); err != nil {
+			err = fmt.Errorf("input: field %s (%s): %w", f.Name, name, err)
+			return
+		}
+	}
+	return
+}
+
+// assign stores val in dst, converting it to the type of dst.
+func assign(dst reflect.Value, val any) (err error) {
+	if val == nil {
+		return
+	}
+	src := reflect.ValueOf(val)
+	typ := dst.Type()
+	if typ == durationType {
+		err = assignDuration(dst, src)
+		return
+	}
+	if typ == colorType && src.Kind() == reflect.String {
+		var c Color
+		if c, err = ParseColor(src.String()); err == nil {
+			dst.Set(reflect.ValueOf(c))
+		}
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		ptr := reflect.New(typ.Elem())
+		if err = assign(ptr.Elem(), val); err == nil {
+			dst.Set(ptr)
+		}
+		return
+	case reflect.Interface:
+		if src.Type().Implements(typ) {
+			dst.Set(src)
+			return
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isNumber(src.Type()) {
+			var n int64
+			if n, err = toInt64(src); err == nil && dst.OverflowInt(n) {
+				err = fmt.Errorf("%v overflows %s", val, typ)
+			}
+			if err == nil {
+				dst.SetInt(n)
+			}
+			return
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if isNumber(src.Type()) {
+			var n uint64
+			if n, err = toUint64(src); err == nil && dst.OverflowUint(n) {
+				err = fmt.Errorf("%v overflows %s", val, typ)
+			}
+			if err == nil {
+				dst.SetUint(n)
+			}
+			return
+		}
+	case reflect.Float32, reflect.Float64:
+		if isNumber(src.Type()) {
+			dst.Set(src.Convert(typ))
+			return
+		}
+	case reflect.Bool:
+		if src.Kind() == reflect.Bool {
+			dst.SetBool(src.Bool())
+			return
+		}
+	case reflect.String:
+		switch src.Kind() {
+		case reflect.String:
+			dst.SetString(src.String())
+		case reflect.Struct:
+			if c, ok := val.(Color); ok {
+				dst.SetString(c.Hex())
+			} else {
+				err = fmt.Errorf("cannot use %s as string", src.Type())
+			}
+		case reflect.Slice, reflect.Map:
+			err = fmt.Errorf("cannot use %s as string", src.Type())
+		default:
+			dst.SetString(fmt.Sprint(val))
+		}
+		return
+	case reflect.Slice:
+		err = assignSlice(dst, src)
+		return
+	case reflect.Map:
+		if src.Kind() == reflect.Map {
+			err = assignMap(dst, src)
+			return
+		}
+	case reflect.Struct:
+		if m, ok := val.(map[string]any); ok {
+			err = bindStruct(dst, m)
+			return
+		}
+	}
+	if src.Type().AssignableTo(typ) {
+		dst.Set(src)
+		return
+	}
+	err = fmt.Errorf("cannot use %s as %s", src.Type(), typ)
+	return
+}
+
+func toInt64(src reflect.Value) (n int64, err error) {
+	switch {
+	case src.CanInt():
+		n = src.Int()
+	case src.CanUint():
+		if src.Uint() > math.MaxInt64 {
+			err = fmt.Errorf("%v overflows int64", src.Uint())
+		}
+		n = int64(src.Uint())
+	default:
+		f := src.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			err = fmt.Errorf("%v is not an integer", f)
+		}
+		n = int64(f)
+	}
+	return
+}
+
+func toUint64(src reflect.Value) (n uint64, err error) {
+	switch {
+	case src.CanUint():
+		n = src.Uint()
+	case src.CanInt():
+		if src.Int() < 0 {
+			err = fmt.Errorf("%v is negative", src.Int())
+		}
+		n = uint64(src.Int())
+	default:
+		f := src.Float()
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+			err = fmt.Errorf("%v is not an unsigned integer", f)
+		}
+		n = uint64(f)
+	}
+	return
+}
+
+func assignDuration(dst reflect.Value, src reflect.Value) (err error) {
+	switch {
+	case src.Kind() == reflect.String:
+		var d time.Duration
+		if d, err = ParseDuration(src.String()); err == nil {
+			dst.SetInt(int64(d))
+		}
+	case src.Type() == durationType:
+		dst.Set(src)
+	case isInteger(src.Type()):
+		var n int64
+		if n, err = toInt64(src); err == nil {
+			dst.SetInt(int64(time.Duration(n) * time.Second))
+		}
+	case isFloat(src.Type()):
+		dst.SetInt(int64(src.Float() * float64(time.Second)))
+	default:
+		err = fmt.Errorf("cannot use %s as %s", src.Type(), durationType)
+	}
+	return
+}
+
+func assignSlice(dst reflect.Value, src reflect.Value) (err error) {
+	typ := dst.Type()
+	if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+		// A single value binds as a one element slice.
+		out := reflect.MakeSlice(typ, 1, 1)
+		if err = assign(out.Index(0), src.Interface()); err == nil {
+			dst.Set(out)
+		}
+		return
+	}
+	out := reflect.MakeSlice(typ, src.Len(), src.Len())
+	for x := 0; x < src.Len(); x++ {
+		if err = assign(out.Index(x), src.Index(x).Interface()); err != nil {
+			err = fmt.Errorf("index %d: %w", x, err)
+			return
+		}
+	}
+	dst.Set(out)
+	return
+}
+
+func assignMap(dst reflect.Value, src reflect.Value) (err error) {
+	typ := dst.Type()
+	out := reflect.MakeMapWithSize(typ, src.Len())
+	iter := src.MapRange()
+	for iter.Next() {
+		k := reflect.New(typ.Key()).Elem()
+		if err = assign(k, iter.Key().Interface()); err != nil {
+			return
+		}
+		v := reflect.New(typ.Elem()).Elem()
+		if err = assign(v, iter.Value().Interface()); err != nil {
+			err = fmt.Errorf("key %v: %w", iter.Key().Interface(), err)
+			return
+		}
+		out.SetMapIndex(k, v)
+	}
+	dst.Set(out)
+	return
+}
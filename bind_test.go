@@ -0,0 +1,73 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type bindTarget struct {
+	Port  uint16         `input:"port"`
+	Small int8           `input:"small"`
+	Ratio float32        `input:"ratio"`
+	Name  *string        `input:"name"`
+	Tags  []string       `input:"tags"`
+	Wait  time.Duration  `input:"wait"`
+	Opts  map[string]int `input:"opts"`
+	Sub   struct {
+		A int `input:"a"`
+	} `input:"sub"`
+	Skip int `input:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	name := "bob"
+	tests := []struct {
+		format, line string
+		want         func(d *bindTarget)
+		err          string
+	}{
+		{"run ${port:Int} ${small:Int} ${ratio:Float} ${name}", "run 8080 5 0.5 bob", func(d *bindTarget) {
+			d.Port, d.Small, d.Ratio, d.Name = 8080, 5, 0.5, &name
+		}, ""},
+		{"tag ${tags...}", "tag a b", func(d *bindTarget) { d.Tags = []string{"a", "b"} }, ""},
+		{"wait ${wait}", "wait 1h30m", func(d *bindTarget) { d.Wait = 90 * time.Minute }, ""},
+		{"wait ${wait:Duration}", "wait 90s", func(d *bindTarget) { d.Wait = 90 * time.Second }, ""},
+		{"wait ${wait:Int}", "wait 5", func(d *bindTarget) { d.Wait = 5 * time.Second }, ""},
+		{"wait ${wait:String}", "wait '2 days'", func(d *bindTarget) { d.Wait = 48 * time.Hour }, ""},
+		{"wait ${wait:String}", "wait 1d", func(d *bindTarget) { d.Wait = 24 * time.Hour }, ""},
+		{"wait ${wait:String}", "wait soon", nil, `input: field Wait (wait): input: bad duration "soon": missing number`},
+		{`set ${opts:Map} ${sub:Map}`, `set {"x":1} {"a":2}`, func(d *bindTarget) {
+			d.Opts, d.Sub.A = map[string]int{"x": 1}, 2
+		}, ""},
+		{"run ${Skip:Int}", "run 1", func(d *bindTarget) {}, ""},
+		{"run ${small:Int}", "run 300", nil, "input: field Small (small): 300 overflows int8"},
+		{"run ${port:Int}", "run -1", nil, "input: field Port (port): -1 is negative"},
+		{"run ${small:Int}", "run x", nil, `input: "run x" does not match "run ${small:Int}": token 1: small expects Int, got String "x"`},
+	}
+	for _, tt := range tests {
+		var got bindTarget
+		err := Unmarshal(tt.format, tt.line, &got)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("%q: got error %v, want %s", tt.line, err, tt.err)
+			}
+			continue
+		}
+		var want bindTarget
+		tt.want(&want)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %+v, %v, want %+v", tt.line, got, err, want)
+		}
+	}
+}
+
+func TestBindNeedsStructPointer(t *testing.T) {
+	in, _ := MustCompile("run ${n:Int}").MatchString("run 1")
+	var n int
+	for _, dst := range []any{nil, bindTarget{}, &n, (*bindTarget)(nil)} {
+		if err := in.Bind(dst); err == nil {
+			t.Errorf("Bind(%#v) did not fail", dst)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Coercion says how far a token may be converted to reach the Kind of its
+// placeholder.
+type Coercion uint8
+
+const (
+	// Strict takes a token only when it already is of the Kind. Integers
+	// are one family: a non-negative Int is taken as a Uint.
+	Strict Coercion = iota
+	// Lenient also converts strings holding a number or a bool ("42",
+	// 'true'), Ints to Floats, and single values to one-element Arrays.
+	Lenient
+)
+
+// errNotKind is the error of a token rejected by a Strict placeholder.
+var errNotKind = errors.New("not of kind")
+
+// WithCoercion sets how tokens are converted to the Kind of their
+// placeholder, defaults included. Patterns are Strict unless compiled
+// WithCoercion(Lenient).
+func WithCoercion(mode Coercion) Option {
+	return func(p *Pattern) {
+		p.coercion = mode
+	}
+}
+
+// coerce converts val to Kind k as far as mode allows. coerced reports
+// whether val had to be converted.
+func coerce(k Kind, val any, mode Coercion) (out any, coerced bool, err error) {
+	if acceptsKind(k, val) {
+		out = val
+		return
+	}
+	if mode != Lenient {
+		err = fmt.Errorf("%w %s", errNotKind, KindString(k))
+		return
+	}
+	str, isStr := val.(string)
+	str = strings.TrimSpace(str)
+	var er error
+	switch {
+	case k == Float && isInteger(reflect.TypeOf(val)):
+		out = reflect.ValueOf(val).Convert(floatType).Float()
+	case k == Float && isStr:
+		out, er = strconv.ParseFloat(str, 64)
+	case (k == Int || k == Uint) && isStr:
+		if out, er = ParseLiteral(str); er == nil && !acceptsKind(k, out) {
+			er = errNotKind
+		}
+	case k == Bool && isStr:
+		out, er = strconv.ParseBool(str)
+	case k == Array && val != nil:
+		out = []any{val}
+	default:
+		er = errNotKind
+	}
+	if er != nil {
+		out, err = nil, fmt.Errorf("cannot convert %s %s to %s", KindString(kindOf(val)), quoteValue(val), KindString(k))
+		return
+	}
+	coerced = true
+	return
+}
+
+func quoteValue(val any) (str string) {
+	if s, ok := val.(string); ok {
+		str = strconv.Quote(s)
+	} else {
+		str = fmt.Sprint(val)
+	}
+	return
+}
@@ -0,0 +1,68 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoercion(t *testing.T) {
+	tests := []struct {
+		format string
+		mode   Coercion
+		line   string
+		want   any
+		ok     bool
+	}{
+		{"${v:Int}", Strict, "42", 42, true},
+		{"${v:Int}", Strict, `"42"`, nil, false},
+		{"${v:Int}", Lenient, `"42"`, 42, true},
+		{"${v:Uint}", Strict, "42", 42, true},
+		{"${v:Uint}", Strict, "-1", nil, false},
+		{"${v:Float}", Strict, "2", nil, false},
+		{"${v:Float}", Lenient, "2", 2.0, true},
+		{"${v:Bool}", Strict, "'true'", nil, false},
+		{"${v:Bool}", Lenient, "'true'", true, true},
+		{"${v:Array}", Strict, "[1,2,3]", []any{1, 2, 3}, true},
+		{"${v:Array}", Strict, "5", nil, false},
+		{"${v:Array}", Lenient, "5", []any{5}, true},
+		{"${v:Array}", Lenient, "[1,2,3]", []any{1, 2, 3}, true},
+		{"${v:String}", Strict, "12", nil, false},
+		{"${v:String}", Strict, "abc", "abc", true},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format, WithCoercion(tt.mode)).MatchString(tt.line)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s %q (mode %d): got error %v", tt.format, tt.line, tt.mode, err)
+			continue
+		}
+		if !tt.ok {
+			continue
+		}
+		if got := in.Get("v").Value; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q (mode %d): got %#v, want %#v", tt.format, tt.line, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestCoercionScore(t *testing.T) {
+	p := MustCompile("${v:Float}", WithCoercion(Lenient))
+	exact, _ := p.MatchString("2.0")
+	conv, _ := p.MatchString("2")
+	if !(exact.Score() > conv.Score()) || !conv.Report().Slots[0].Coerced {
+		t.Errorf("coerced token scores %v, exact %v", conv.Score(), exact.Score())
+	}
+}
+
+func TestCoercionAppliesToDefaults(t *testing.T) {
+	if _, err := Compile("${v:Int=\"5\"}"); err == nil {
+		t.Error("a strict default of the wrong kind compiled")
+	}
+	p, err := Compile("${v:Int=\"5\"}", WithCoercion(Lenient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, _ := p.MatchString("")
+	if got := in.Get("v").Value; got != 5 {
+		t.Errorf("default is %#v, want 5", got)
+	}
+}
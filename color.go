@@ -0,0 +1,301 @@
+package input
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Color is the value of an RGBHex placeholder: 8-bit red, green, blue and
+// alpha channels, not premultiplied. It implements image/color.Color.
+type Color struct {
+	R, G, B, A uint8
+}
+
+// ParseColor reads a CSS color:
+//
+//	#rgb #rgba #rrggbb #rrggbbaa
+//	rgb(255, 0, 0)  rgb(100% 0% 0% / 50%)  rgba(255, 0, 0, 0.5)
+//	hsl(120, 100%, 50%)  hsl(120deg 100% 50% / 0.5)  hsla(120, 100%, 50%, 0.5)
+//	red  rebeccapurple  transparent
+//
+// Names and function names are case insensitive.
+func ParseColor(s string) (c Color, err error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case strings.HasPrefix(str, "#"):
+		c, err = parseHexColor(str[1:])
+	case strings.HasSuffix(str, ")"):
+		c, err = parseColorFunc(str)
+	default:
+		if rgb, ok := namedColors[str]; ok {
+			c = Color{uint8(rgb >> 16), uint8(rgb >> 8), uint8(rgb), 0xff}
+		} else if str == "transparent" {
+			c = Color{}
+		} else {
+			err = fmt.Errorf("unknown color name %q", s)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("input: bad color %q: %w", s, err)
+	}
+	return
+}
+
+func parseHexColor(hex string) (c Color, err error) {
+	n, er := strconv.ParseUint(hex, 16, 32)
+	if er != nil {
+		err = fmt.Errorf("%q is not hexadecimal", hex)
+		return
+	}
+	switch len(hex) {
+	case 3, 4:
+		if len(hex) == 3 {
+			n = n<<4 | 0xf
+		}
+		// Each digit stands for a doubled one: f is ff.
+		c = Color{uint8(n>>12&0xf) * 0x11, uint8(n>>8&0xf) * 0x11, uint8(n>>4&0xf) * 0x11, uint8(n&0xf) * 0x11}
+	case 6, 8:
+		if len(hex) == 6 {
+			n = n<<8 | 0xff
+		}
+		c = Color{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}
+	default:
+		err = fmt.Errorf("want 3, 4, 6 or 8 hex digits, got %d", len(hex))
+	}
+	return
+}
+
+// parseColorFunc reads rgb(), rgba(), hsl() and hsla(). Arguments are
+// separated by commas or spaces, with an optional `/ alpha` at the end.
+func parseColorFunc(str string) (c Color, err error) {
+	open := strings.IndexByte(str, '(')
+	if open < 0 {
+		err = fmt.Errorf("missing `(`")
+		return
+	}
+	name := strings.TrimSpace(str[:open])
+	args := strings.FieldsFunc(strings.ReplaceAll(str[open+1:len(str)-1], "/", ","), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(args) != 3 && len(args) != 4 {
+		err = fmt.Errorf("%s() takes 3 or 4 arguments, got %d", name, len(args))
+		return
+	}
+	alpha := 1.0
+	if len(args) == 4 {
+		if alpha, err = colorArg(args[3], 1); err != nil {
+			return
+		}
+	}
+	var v [3]float64
+	switch name {
+	case "rgb", "rgba":
+		for x := range v {
+			if v[x], err = colorArg(args[x], 255); err != nil {
+				return
+			}
+		}
+		c = Color{channel(v[0] / 255), channel(v[1] / 255), channel(v[2] / 255), channel(alpha)}
+	case "hsl", "hsla":
+		if v[0], err = hueArg(args[0]); err != nil {
+			return
+		}
+		for x := 1; x < 3; x++ {
+			if !strings.HasSuffix(args[x], "%") {
+				err = fmt.Errorf("saturation and lightness must be percentages, got %q", args[x])
+				return
+			}
+			if v[x], err = colorArg(args[x], 1); err != nil {
+				return
+			}
+		}
+		c = ColorFromHSL(v[0], v[1], v[2])
+		c.A = channel(alpha)
+	default:
+		err = fmt.Errorf("unknown color function %s()", name)
+	}
+	return
+}
+
+// colorArg reads a number, or a percentage of max, clamped to [0, max].
+func colorArg(arg string, max float64) (f float64, err error) {
+	pct := strings.HasSuffix(arg, "%")
+	if f, err = strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64); err != nil {
+		err = fmt.Errorf("%q is not a number", arg)
+		return
+	}
+	if pct {
+		f = f / 100 * max
+	}
+	f = math.Max(0, math.Min(max, f))
+	return
+}
+
+// hueArg reads an angle in degrees, the default unit, or in rad or turn.
+func hueArg(arg string) (h float64, err error) {
+	unit := 1.0
+	for _, u := range []struct {
+		suffix string
+		scale  float64
+	}{{"deg", 1}, {"rad", 180 / math.Pi}, {"turn", 360}} {
+		if strings.HasSuffix(arg, u.suffix) {
+			arg, unit = strings.TrimSuffix(arg, u.suffix), u.scale
+			break
+		}
+	}
+	if h, err = strconv.ParseFloat(arg, 64); err != nil {
+		err = fmt.Errorf("%q is not an angle", arg)
+	}
+	h *= unit
+	return
+}
+
+// channel turns a fraction into an 8-bit channel.
+func channel(f float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, f)) * 255))
+}
+
+// ColorFromHSL returns the opaque color of hue h in degrees and saturation
+// s and lightness l between 0 and 1.
+func ColorFromHSL(h, s, l float64) (c Color) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = chroma, x
+	case h < 120:
+		r, g = x, chroma
+	case h < 180:
+		g, b = chroma, x
+	case h < 240:
+		g, b = x, chroma
+	case h < 300:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	m := l - chroma/2
+	c = Color{channel(r + m), channel(g + m), channel(b + m), 0xff}
+	return
+}
+
+// HSL returns the hue of c in degrees and its saturation and lightness
+// between 0 and 1.
+func (c Color) HSL() (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	d := max - min
+	if d == 0 {
+		return
+	}
+	s = d / (1 - math.Abs(2*l-1))
+	switch max {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	if h *= 60; h < 0 {
+		h += 360
+	}
+	return
+}
+
+// Hex writes c as #rrggbb, or #rrggbbaa when it is not opaque.
+func (c Color) Hex() (str string) {
+	str = "#" + fmt.Sprintf(KindFmtSymbol(RGBHex), c.R, c.G, c.B)
+	if c.A != 0xff {
+		str += fmt.Sprintf("%02x", c.A)
+	}
+	return
+}
+
+func (c Color) String() string {
+	return c.Hex()
+}
+
+// RGBA implements image/color.Color.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A}.RGBA()
+}
+
+// Name is the CSS name of c, or "" when c has none.
+func (c Color) Name() (name string) {
+	if c.A != 0xff {
+		if c == (Color{}) {
+			name = "transparent"
+		}
+		return
+	}
+	rgb := uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+	for _, n := range colorNames {
+		if namedColors[n] == rgb {
+			name = n
+			return
+		}
+	}
+	return
+}
+
+// colorNames are the keys of namedColors in order, for completion and
+// stable lookups.
+var colorNames = func() (names []string) {
+	for n := range namedColors {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return
+}()
+
+// namedColors are the CSS named colors as 0xrrggbb.
+var namedColors = map[string]uint32{
+	"aliceblue": 0xf0f8ff, "antiquewhite": 0xfaebd7, "aqua": 0x00ffff, "aquamarine": 0x7fffd4,
+	"azure": 0xf0ffff, "beige": 0xf5f5dc, "bisque": 0xffe4c4, "black": 0x000000,
+	"blanchedalmond": 0xffebcd, "blue": 0x0000ff, "blueviolet": 0x8a2be2, "brown": 0xa52a2a,
+	"burlywood": 0xdeb887, "cadetblue": 0x5f9ea0, "chartreuse": 0x7fff00, "chocolate": 0xd2691e,
+	"coral": 0xff7f50, "cornflowerblue": 0x6495ed, "cornsilk": 0xfff8dc, "crimson": 0xdc143c,
+	"cyan": 0x00ffff, "darkblue": 0x00008b, "darkcyan": 0x008b8b, "darkgoldenrod": 0xb8860b,
+	"darkgray": 0xa9a9a9, "darkgreen": 0x006400, "darkgrey": 0xa9a9a9, "darkkhaki": 0xbdb76b,
+	"darkmagenta": 0x8b008b, "darkolivegreen": 0x556b2f, "darkorange": 0xff8c00, "darkorchid": 0x9932cc,
+	"darkred": 0x8b0000, "darksalmon": 0xe9967a, "darkseagreen": 0x8fbc8f, "darkslateblue": 0x483d8b,
+	"darkslategray": 0x2f4f4f, "darkslategrey": 0x2f4f4f, "darkturquoise": 0x00ced1, "darkviolet": 0x9400d3,
+	"deeppink": 0xff1493, "deepskyblue": 0x00bfff, "dimgray": 0x696969, "dimgrey": 0x696969,
+	"dodgerblue": 0x1e90ff, "firebrick": 0xb22222, "floralwhite": 0xfffaf0, "forestgreen": 0x228b22,
+	"fuchsia": 0xff00ff, "gainsboro": 0xdcdcdc, "ghostwhite": 0xf8f8ff, "gold": 0xffd700,
+	"goldenrod": 0xdaa520, "gray": 0x808080, "green": 0x008000, "greenyellow": 0xadff2f,
+	"grey": 0x808080, "honeydew": 0xf0fff0, "hotpink": 0xff69b4, "indianred": 0xcd5c5c,
+	"indigo": 0x4b0082, "ivory": 0xfffff0, "khaki": 0xf0e68c, "lavender": 0xe6e6fa,
+	"lavenderblush": 0xfff0f5, "lawngreen": 0x7cfc00, "lemonchiffon": 0xfffacd, "lightblue": 0xadd8e6,
+	"lightcoral": 0xf08080, "lightcyan": 0xe0ffff, "lightgoldenrodyellow": 0xfafad2, "lightgray": 0xd3d3d3,
+	"lightgreen": 0x90ee90, "lightgrey": 0xd3d3d3, "lightpink": 0xffb6c1, "lightsalmon": 0xffa07a,
+	"lightseagreen": 0x20b2aa, "lightskyblue": 0x87cefa, "lightslategray": 0x778899, "lightslategrey": 0x778899,
+	"lightsteelblue": 0xb0c4de, "lightyellow": 0xffffe0, "lime": 0x00ff00, "limegreen": 0x32cd32,
+	"linen": 0xfaf0e6, "magenta": 0xff00ff, "maroon": 0x800000, "mediumaquamarine": 0x66cdaa,
+	"mediumblue": 0x0000cd, "mediumorchid": 0xba55d3, "mediumpurple": 0x9370db, "mediumseagreen": 0x3cb371,
+	"mediumslateblue": 0x7b68ee, "mediumspringgreen": 0x00fa9a, "mediumturquoise": 0x48d1cc, "mediumvioletred": 0xc71585,
+	"midnightblue": 0x191970, "mintcream": 0xf5fffa, "mistyrose": 0xffe4e1, "moccasin": 0xffe4b5,
+	"navajowhite": 0xffdead, "navy": 0x000080, "oldlace": 0xfdf5e6, "olive": 0x808000,
+	"olivedrab": 0x6b8e23, "orange": 0xffa500, "orangered": 0xff4500, "orchid": 0xda70d6,
+	"palegoldenrod": 0xeee8aa, "palegreen": 0x98fb98, "paleturquoise": 0xafeeee, "palevioletred": 0xdb7093,
+	"papayawhip": 0xffefd5, "peachpuff": 0xffdab9, "peru": 0xcd853f, "pink": 0xffc0cb,
+	"plum": 0xdda0dd, "powderblue": 0xb0e0e6, "purple": 0x800080, "rebeccapurple": 0x663399,
+	"red": 0xff0000, "rosybrown": 0xbc8f8f, "royalblue": 0x4169e1, "saddlebrown": 0x8b4513,
+	"salmon": 0xfa8072, "sandybrown": 0xf4a460, "seagreen": 0x2e8b57, "seashell": 0xfff5ee,
+	"sienna": 0xa0522d, "silver": 0xc0c0c0, "skyblue": 0x87ceeb, "slateblue": 0x6a5acd,
+	"slategray": 0x708090, "slategrey": 0x708090, "snow": 0xfffafa, "springgreen": 0x00ff7f,
+	"steelblue": 0x4682b4, "tan": 0xd2b48c, "teal": 0x008080, "thistle": 0xd8bfd8,
+	"tomato": 0xff6347, "turquoise": 0x40e0d0, "violet": 0xee82ee, "wheat": 0xf5deb3,
+	"white": 0xffffff, "whitesmoke": 0xf5f5f5, "yellow": 0xffff00, "yellowgreen": 0x9acd32,
+}
@@ -0,0 +1,70 @@
+package input
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Color
+		ok   bool
+	}{
+		{"#f00", Color{0xff, 0, 0, 0xff}, true},
+		{"#ff000080", Color{0xff, 0, 0, 0x80}, true},
+		{"#F0F8", Color{0xff, 0, 0xff, 0x88}, true},
+		{"rgb(255, 0, 0)", Color{0xff, 0, 0, 0xff}, true},
+		{"rgba(0, 0, 255, 0.5)", Color{0, 0, 0xff, 0x80}, true},
+		{"rgb(100% 0% 0% / 50%)", Color{0xff, 0, 0, 0x80}, true},
+		{"hsl(120, 100%, 50%)", Color{0, 0xff, 0, 0xff}, true},
+		{"HSL(120deg 100% 50% / 0.5)", Color{0, 0xff, 0, 0x80}, true},
+		{"RebeccaPurple", Color{0x66, 0x33, 0x99, 0xff}, true},
+		{"transparent", Color{}, true},
+		{"#12345", Color{}, false},
+		{"rgb(300, 0)", Color{}, false},
+		{"blurple", Color{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestColorNamesAndHSL(t *testing.T) {
+	if n := (Color{0xff, 0, 0, 0xff}).Name(); n != "red" {
+		t.Errorf("Name is %q, want red", n)
+	}
+	if h := (Color{0xff, 0, 0, 0x80}).Hex(); h != "#ff000080" {
+		t.Errorf("Hex is %q", h)
+	}
+	if c := ColorFromHSL(240, 1, 0.5); c != (Color{0, 0, 0xff, 0xff}) {
+		t.Errorf("ColorFromHSL is %v", c)
+	}
+	if h, s, l := (Color{0, 0, 0xff, 0xff}).HSL(); h != 240 || s != 1 || l != 0.5 {
+		t.Errorf("HSL is %v %v %v", h, s, l)
+	}
+}
+
+func TestColorPlaceholder(t *testing.T) {
+	p := MustCompile("paint ${c:Color}")
+	in, err := p.MatchString("paint rgb(0, 128, 0)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := in.Get("c").Value; got != (Color{0, 0x80, 0, 0xff}) {
+		t.Errorf("got %v", got)
+	}
+	var out struct {
+		C Color `input:"c"`
+	}
+	if err = in.Bind(&out); err != nil || out.C != (Color{0, 0x80, 0, 0xff}) {
+		t.Errorf("Bind gave %v, %v", out.C, err)
+	}
+	line, err := p.Render(out)
+	if err != nil || line != "paint #008000" {
+		t.Errorf("Render gave %q, %v", line, err)
+	}
+	if _, err = p.MatchString("paint notacolor"); err == nil {
+		t.Error("a bad color matched")
+	}
+}
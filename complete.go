@@ -0,0 +1,128 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Completion is a candidate for the token under the cursor. Start and End
+// are the byte offsets in the partial line that Text replaces; they are
+// equal when the cursor is between tokens.
+type Completion struct {
+	Text string
+	// Hint is set when Text only describes the expected token, e.g.
+	// `<port:Int>`, and is not meant to be inserted.
+	Hint bool
+	// Description is the format the candidate comes from.
+	Description string
+	Start, End  int
+}
+
+// ProviderFunc lists values for a placeholder that start with prefix.
+type ProviderFunc func(prefix string) []string
+
+// Provide registers fn to list values for every placeholder called name,
+// in any format of r.
+func (r *Router) Provide(name string, fn ProviderFunc) {
+	r.mu.Lock()
+	if r.providers == nil {
+		r.providers = map[string]ProviderFunc{}
+	}
+	r.providers[name] = fn
+	r.mu.Unlock()
+}
+
+// Complete lists candidates for the token at cursor in partial, a line that
+// is being typed and may be incomplete. The tokens before the cursor select
+// the formats still in the running; each one offers its next literal, the
+// enum values of its next placeholder, values from the placeholder's Kind
+// or from Provide, or a hint naming the Kind when there is nothing to list.
+func (r *Router) Complete(partial string, cursor int) (cs []Completion) {
+	if cursor < 0 || cursor > len(partial) {
+		cursor = len(partial)
+	}
+	seen := map[string]bool{}
+	for _, rt := range r.Routes() {
+		p := rt.pattern
+		start, end := cursor, cursor
+		var done []string
+		spans, _ := p.separators().spans(partial)
+		for _, s := range spans {
+			switch {
+			case s.end < cursor:
+				done = append(done, partial[s.start:s.end])
+			case s.start < cursor:
+				start, end = s.start, s.end
+			}
+		}
+		prefix := partial[start:cursor]
+		add := func(text string, hint bool, p *Pattern) {
+			if seen[text] || (!hint && !strings.HasPrefix(text, prefix)) {
+				return
+			}
+			seen[text] = true
+			cs = append(cs, Completion{Text: text, Hint: hint, Description: p.format, Start: start, End: end})
+		}
+		for _, e := range p.next(done) {
+			if e.spec == nil {
+				add(e.literal, false, p)
+				continue
+			}
+			r.mu.RLock()
+			provide := r.providers[e.spec.name]
+			r.mu.RUnlock()
+			n := len(cs)
+			for _, v := range e.spec.candidates(prefix, provide) {
+				add(v, false, p)
+			}
+			if len(cs) == n {
+				add(e.spec.hint(), true, p)
+			}
+		}
+	}
+	return
+}
+
+// next returns the elements that may take the token after done, in the
+// order they are tried. It is empty when done cannot start a line of p.
+func (p *Pattern) next(done []string) (es []element) {
+	a := &alignment{p: p, vars: map[string]*Var{}, w: p.scoreWeights()}
+	m := &matcher{a: a, prog: p.prog, raws: done, exact: true}
+	_, _, end := m.run()
+	seen := map[element]bool{}
+	for _, pc := range end.order {
+		if o := p.prog[pc]; o.code == opWord && !seen[o.n.word[0]] {
+			seen[o.n.word[0]] = true
+			es = append(es, o.n.word[0])
+		}
+	}
+	return
+}
+
+// candidates lists the values the placeholder offers for prefix.
+func (s *varSpec) candidates(prefix string, provide ProviderFunc) (vals []string) {
+	if s.constraint != nil {
+		vals = append(vals, s.constraint.enum...)
+	}
+	if e, ok := kindDef(s.kind); ok && e.def.Complete != nil {
+		vals = append(vals, e.def.Complete(prefix)...)
+	}
+	switch s.kind {
+	case Bool:
+		vals = append(vals, "true", "false")
+	case RGBHex:
+		vals = append(vals, colorNames...)
+	}
+	if provide != nil {
+		vals = append(vals, provide(prefix)...)
+	}
+	return
+}
+
+// hint describes the token the placeholder expects.
+func (s *varSpec) hint() string {
+	if s.constraint != nil && s.constraint.re != nil {
+		return fmt.Sprintf("<%s:%s>", s.name, s.constraint)
+	}
+	return fmt.Sprintf("<%s:%s>", s.name, KindString(s.kind))
+}
@@ -0,0 +1,54 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	r := NewRouter()
+	r.Handle("deploy ${env:String(dev|prod)} ${port:Int}", nil)
+	r.Handle("delete ${file}", nil)
+	r.Handle("set ${flag:Bool}", nil)
+	r.Handle("paint ${c:RGBHex}", nil)
+	r.Provide("file", func(prefix string) (files []string) {
+		for _, f := range []string{"a.txt", "b.txt"} {
+			if strings.HasPrefix(f, prefix) {
+				files = append(files, f)
+			}
+		}
+		return
+	})
+	tests := []struct {
+		partial    string
+		cursor     int
+		want       []string
+		start, end int
+		hint       bool
+	}{
+		{"", 0, []string{"deploy", "delete", "set", "paint"}, 0, 0, false},
+		{"de", 2, []string{"deploy", "delete"}, 0, 2, false},
+		{"deploy ", 7, []string{"dev", "prod"}, 7, 7, false},
+		{"deploy d", 8, []string{"dev"}, 7, 8, false},
+		{"deploy dev ", 11, []string{"<port:Int>"}, 11, 11, true},
+		{"deploy dev 80", 7, []string{"dev", "prod"}, 7, 7, false},
+		{"deploy dev", -1, []string{"dev"}, 7, 10, false},
+		{"delete b", 8, []string{"b.txt"}, 7, 8, false},
+		{"set ", 4, []string{"true", "false"}, 4, 4, false},
+		{"paint re", 8, []string{"rebeccapurple", "red"}, 6, 8, false},
+		{"xx ", 3, nil, 0, 0, false},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range r.Complete(tt.partial, tt.cursor) {
+			got = append(got, c.Text)
+			if c.Start != tt.start || c.End != tt.end || c.Hint != tt.hint {
+				t.Errorf("Complete(%q, %d): %q replaces %d:%d with Hint %v, want %d:%d with Hint %v", tt.partial, tt.cursor, c.Text, c.Start, c.End, c.Hint, tt.start, tt.end, tt.hint)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q, %d) = %q, want %q", tt.partial, tt.cursor, got, tt.want)
+		}
+	}
+}
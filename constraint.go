@@ -0,0 +1,158 @@
+package input
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// constraint is the validation carried by a placeholder:
+//
+//	Int(1..65535)        a range of values, either end may be left open
+//	String(3..20)        a range of lengths for strings, arrays and maps
+//	String(dev|prod)     an enum of allowed tokens
+//	/[a-z]{3}-\d+/       a regexp the whole token must match
+//
+// On a variadic placeholder a range bounds the number of tokens, while an
+// enum or regexp applies to each of them.
+type constraint struct {
+	min, max *float64
+	enum     []string
+	re       *regexp.Regexp
+	text     string
+}
+
+func (c *constraint) String() string {
+	return c.text
+}
+
+func parseConstraint(str string) (c *constraint, err error) {
+	c = &constraint{text: str}
+	if lo, hi, ok := strings.Cut(str, ".."); ok {
+		if c.min, err = parseBound(lo); err == nil {
+			c.max, err = parseBound(hi)
+		}
+		if err == nil && c.min != nil && c.max != nil && *c.min > *c.max {
+			err = fmt.Errorf("empty range %q", str)
+		}
+		if err != nil {
+			c = nil
+		}
+		return
+	}
+	for _, v := range strings.Split(str, "|") {
+		if v = strings.TrimSpace(v); v == "" {
+			c, err = nil, fmt.Errorf("empty choice in %q", str)
+			return
+		}
+		c.enum = append(c.enum, v)
+	}
+	return
+}
+
+func parseBound(str string) (b *float64, err error) {
+	if str = strings.TrimSpace(str); str == "" {
+		return
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		err = fmt.Errorf("bad range bound %q", str)
+		return
+	}
+	b = &f
+	return
+}
+
+// parseRegexp reads a `/regexp/` from the start of str and returns what
+// follows it.
+func parseRegexp(str string) (c *constraint, rest string, err error) {
+	end := -1
+	for x := 1; x < len(str) && end < 0; x++ {
+		switch str[x] {
+		case '\\':
+			x++
+		case '/':
+			end = x
+		}
+	}
+	if end < 0 {
+		err = fmt.Errorf("unclosed regexp %q", str)
+		return
+	}
+	re, err := regexp.Compile(`^(?:` + str[1:end] + `)$`)
+	if err != nil {
+		err = fmt.Errorf("bad regexp %q: %v", str[:end+1], err)
+		return
+	}
+	c, rest = &constraint{re: re, text: str[:end+1]}, str[end+1:]
+	return
+}
+
+// check validates the value a placeholder captured from raw.
+func (s *varSpec) check(val any, raw string) (err error) {
+	c := s.constraint
+	if c == nil {
+		return
+	}
+	if s.variadic {
+		rv := reflect.ValueOf(val)
+		for x := 0; x < rv.Len() && err == nil; x++ {
+			err = c.checkToken(rv.Index(x).Interface(), "")
+		}
+		if err == nil {
+			err = c.checkRange(float64(rv.Len()), "count")
+		}
+		return
+	}
+	if err = c.checkToken(val, raw); err != nil {
+		return
+	}
+	rv := reflect.ValueOf(val)
+	switch {
+	case !rv.IsValid():
+	case rv.Kind() == reflect.String:
+		err = c.checkRange(float64(utf8.RuneCountInString(rv.String())), "length")
+	case rv.Kind() == reflect.Slice, rv.Kind() == reflect.Array, rv.Kind() == reflect.Map:
+		err = c.checkRange(float64(rv.Len()), "length")
+	case isNumber(rv.Type()):
+		err = c.checkRange(rv.Convert(floatType).Float(), "value")
+	}
+	return
+}
+
+// checkToken applies an enum or regexp to a single value. raw is the token
+// as typed, used when the value is not a string.
+func (c *constraint) checkToken(val any, raw string) (err error) {
+	if c.enum == nil && c.re == nil {
+		return
+	}
+	str, ok := val.(string)
+	if !ok {
+		if str = raw; str == "" {
+			str = fmt.Sprint(val)
+		}
+	}
+	if c.re != nil && !c.re.MatchString(str) {
+		err = fmt.Errorf("%q does not match %s", str, c.text)
+		return
+	}
+	if c.enum != nil {
+		for _, e := range c.enum {
+			if e == str {
+				return
+			}
+		}
+		err = fmt.Errorf("%q is not one of %s", str, strings.Join(c.enum, ", "))
+	}
+	return
+}
+
+func (c *constraint) checkRange(n float64, what string) (err error) {
+	if (c.min != nil && n < *c.min) || (c.max != nil && n > *c.max) {
+		err = fmt.Errorf("%s %v is out of range %s", what, n, c.text)
+	}
+	return
+}
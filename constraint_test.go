@@ -0,0 +1,74 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConstraints(t *testing.T) {
+	tests := []struct {
+		format, line string
+		want         any
+		err          string
+	}{
+		{"listen ${port:Int(1..65535)}", "listen 80", 80, ""},
+		{"listen ${port:Int(1..65535)}", "listen 0", nil, "token 1: port: value 0 is out of range 1..65535"},
+		{"listen ${port:Int(1..65535)}", "listen 70000", nil, "token 1: port: value 70000 is out of range 1..65535"},
+		{"listen ${port:Int(1..)}", "listen 0", nil, "token 1: port: value 0 is out of range 1.."},
+		{"ratio ${port:Float(0..1)}", "ratio 1.5", nil, "token 1: port: value 1.5 is out of range 0..1"},
+		{"mode ${port:String(dev|staging|prod)}", "mode dev", "dev", ""},
+		{"mode ${port:String(dev|staging|prod)}", "mode qa", nil, `token 1: port: "qa" is not one of dev, staging, prod`},
+		{`get ${port:/[a-z]{3}-\d+/}`, "get abc-12", "abc-12", ""},
+		{`get ${port:/[a-z]{3}-\d+/}`, "get ab-12", nil, `token 1: port: "ab-12" does not match /[a-z]{3}-\d+/`},
+		{"name ${port:String(2..4)}", "name bob", "bob", ""},
+		{"name ${port:String(2..4)}", "name bobby", nil, "token 1: port: length 5 is out of range 2..4"},
+		{"tags ${port:Array(..2)}", "tags [1,2,3]", nil, "token 1: port: length 3 is out of range ..2"},
+		{"tag ${port...:String(2..3)}", "tag a", []string{}, "token 1: port: count 1 is out of range 2..3"},
+		{"tag ${port...:String(dev|prod)}", "tag dev qa", []string{"dev"}, `token 2: port: "qa" is not one of dev, prod`},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format).MatchString(tt.line)
+		if me, ok := err.(*MatchError); tt.err != "" && (!ok || me.Errors[0].Reason != ConstraintViolation || me.Errors[0].Error() != tt.err) {
+			t.Errorf("%s %q: got error %v, want %s", tt.format, tt.line, err, tt.err)
+		} else if tt.err == "" && err != nil {
+			t.Errorf("%s %q: %v", tt.format, tt.line, err)
+		}
+		if got := in.Get("port").Value; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %q: got %#v, want %#v", tt.format, tt.line, got, tt.want)
+		}
+		if tt.err != "" && in.Score() >= 1 {
+			t.Errorf("%s %q: a failed constraint scores %v", tt.format, tt.line, in.Score())
+		}
+	}
+}
+
+func TestConstraintCompileErrors(t *testing.T) {
+	tests := []struct {
+		format, err string
+	}{
+		{"x ${a:Int(a..b)}", `input: bad range bound "a" in ${a:Int(a..b)}`},
+		{"x ${a:Int(5..1)}", `input: empty range "5..1" in ${a:Int(5..1)}`},
+		{"x ${a:String(dev||prod)}", `input: empty choice in "dev||prod" in ${a:String(dev||prod)}`},
+		{"x ${a:/abc}", `input: unclosed regexp "/abc" in ${a:/abc}`},
+		{"x ${a:/a**/}", "input: bad regexp \"/a**/\": error parsing regexp: invalid nested repetition operator: `**` in ${a:/a**/}"},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.format); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got error %v, want %s", tt.format, err, tt.err)
+		}
+	}
+}
+
+func TestEnumFeedsCompletionAndHelp(t *testing.T) {
+	r := NewRouter()
+	r.Handle("mode ${m:String(dev|staging|prod)}", nil)
+	if cs := r.Complete("mode s", 6); len(cs) != 1 || cs[0].Text != "staging" || cs[0].Start != 5 || cs[0].End != 6 {
+		t.Errorf("Complete: got %+v", cs)
+	}
+	var b strings.Builder
+	MustCompile("listen ${port:Int(1..65535)} ${m:String(dev|prod)}").WriteHelp(&b, TextHelp)
+	if help := b.String(); !strings.Contains(help, "Int, 1..65535") || !strings.Contains(help, "String, dev|prod") {
+		t.Errorf("help does not show the constraints:\n%s", help)
+	}
+}
@@ -0,0 +1,136 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Reason says why a single token failed to match.
+type Reason uint8
+
+const (
+	// MissingToken means the input ended before the placeholder or literal.
+	MissingToken Reason = iota + 1
+	// ExtraToken means the input has a token past the end of the format.
+	ExtraToken
+	// KindMismatch means a placeholder got a value of the wrong Kind.
+	KindMismatch
+	// LiteralMismatch means a literal word of the format was not typed.
+	LiteralMismatch
+	// ConstraintViolation means a value of the right Kind broke the range,
+	// enum or regexp of its placeholder.
+	ConstraintViolation
+	// UnknownFlag means a flag names no placeholder of the format.
+	UnknownFlag
+	// RepeatedFlag means a placeholder that takes one value is named by
+	// more than one flag.
+	RepeatedFlag
+	// Unterminated means the last token opens a quote or bracket, given as
+	// Literal, that the line never closes.
+	Unterminated
+)
+
+func (r Reason) String() (str string) {
+	switch r {
+	case MissingToken:
+		str = "missing token"
+	case ExtraToken:
+		str = "extra token"
+	case KindMismatch:
+		str = "kind mismatch"
+	case LiteralMismatch:
+		str = "literal mismatch"
+	case ConstraintViolation:
+		str = "constraint violation"
+	case UnknownFlag:
+		str = "unknown flag"
+	case RepeatedFlag:
+		str = "repeated flag"
+	case Unterminated:
+		str = "unterminated token"
+	default:
+		str = fmt.Sprint(uint8(r))
+	}
+	return
+}
+
+// TokenError describes one token that failed to match a Pattern.
+type TokenError struct {
+	Reason Reason
+	// Pos is the position of the token in the input.
+	Pos int
+	// Name is the placeholder name, empty when a literal was expected.
+	Name string
+	// Literal is the expected literal word, empty for placeholders, or the
+	// quote or bracket left open for Unterminated.
+	Literal  string
+	Expected Kind
+	// Token is the raw token as typed, empty when it is missing.
+	Token string
+	Got   Kind
+	// Suggest is the literal a mistyped token is close to, when the Pattern
+	// was compiled WithFuzzy.
+	Suggest string
+	// Err is the reason a token was rejected by its Kind, when known.
+	Err error
+}
+
+func (e *TokenError) Error() (str string) {
+	switch e.Reason {
+	case MissingToken:
+		if e.Name != "" {
+			str = fmt.Sprintf("token %d: missing %s (%s)", e.Pos, e.Name, KindString(e.Expected))
+		} else {
+			str = fmt.Sprintf("token %d: missing %q", e.Pos, e.Literal)
+		}
+	case ExtraToken:
+		str = fmt.Sprintf("token %d: unexpected %q", e.Pos, e.Token)
+	case KindMismatch:
+		str = fmt.Sprintf("token %d: %s expects %s, got %s %q", e.Pos, e.Name, KindString(e.Expected), KindString(e.Got), e.Token)
+		if e.Err != nil && !errors.Is(e.Err, errNotKind) {
+			str += ": " + e.Err.Error()
+		}
+	case LiteralMismatch:
+		str = fmt.Sprintf("token %d: expected %q, got %q", e.Pos, e.Literal, e.Token)
+		if e.Suggest != "" {
+			str += fmt.Sprintf(", did you mean `%s`?", e.Suggest)
+		}
+	case ConstraintViolation:
+		str = fmt.Sprintf("token %d: %s: %v", e.Pos, e.Name, e.Err)
+	case UnknownFlag:
+		str = fmt.Sprintf("token %d: unknown flag %q", e.Pos, e.Token)
+	case RepeatedFlag:
+		str = fmt.Sprintf("token %d: %s is given more than once", e.Pos, e.Name)
+	case Unterminated:
+		str = fmt.Sprintf("token %d: %s is never closed in %q", e.Pos, e.Literal, e.Token)
+	default:
+		str = fmt.Sprintf("token %d: %s", e.Pos, e.Reason)
+	}
+	return
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// MatchError lists every token of a line that failed to match a Pattern.
+type MatchError struct {
+	Format string
+	Line   string
+	// LineNo is the 1-based number of the line in its source when it was
+	// read by a Scanner, and 0 otherwise.
+	LineNo int
+	Errors []*TokenError
+}
+
+func (e *MatchError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for x, te := range e.Errors {
+		msgs[x] = te.Error()
+	}
+	if e.LineNo > 0 {
+		return fmt.Sprintf("input: line %d: %q does not match %q: %s", e.LineNo, e.Line, e.Format, strings.Join(msgs, "; "))
+	}
+	return fmt.Sprintf("input: %q does not match %q: %s", e.Line, e.Format, strings.Join(msgs, "; "))
+}
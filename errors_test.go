@@ -0,0 +1,75 @@
+package input
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMatchErrors(t *testing.T) {
+	tests := []struct {
+		format, line string
+		want         []Reason
+	}{
+		{"deploy ${env} ${port:Int}", "deploy prod 80", nil},
+		{"deploy ${env} ${port:Int}", "deploy prod", []Reason{MissingToken}},
+		{"deploy ${env} ${port:Int}", "deploy", []Reason{MissingToken, MissingToken}},
+		{"deploy ${env} ${port:Int}", "deploy prod 80 now", []Reason{ExtraToken}},
+		{"deploy ${env} ${port:Int}", "deploy prod eighty", []Reason{KindMismatch}},
+		{"deploy ${env} ${port:Int}", "launch prod 80", []Reason{LiteralMismatch}},
+	}
+	for _, tt := range tests {
+		p := MustCompile(tt.format)
+		in, err := p.MatchString(tt.line)
+		if tt.want == nil {
+			if err != nil || in.Err() != nil {
+				t.Errorf("%q: unexpected error %v", tt.line, err)
+			}
+			continue
+		}
+		var me *MatchError
+		if !errors.As(err, &me) {
+			t.Errorf("%q: got %v, want a *MatchError", tt.line, err)
+			continue
+		}
+		var got []Reason
+		for _, te := range me.Errors {
+			got = append(got, te.Reason)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got reasons %v, want %v (%v)", tt.line, got, tt.want, err)
+		}
+		if in.Err() != err {
+			t.Errorf("%q: Input.Err is %v, want %v", tt.line, in.Err(), err)
+		}
+	}
+}
+
+func TestTokenErrorMessages(t *testing.T) {
+	tests := []struct {
+		te   TokenError
+		want string
+	}{
+		{TokenError{Reason: MissingToken, Pos: 1, Name: "env", Expected: String}, `token 1: missing env (String)`},
+		{TokenError{Reason: MissingToken, Pos: 0, Literal: "deploy"}, `token 0: missing "deploy"`},
+		{TokenError{Reason: ExtraToken, Pos: 3, Token: "now"}, `token 3: unexpected "now"`},
+		{TokenError{Reason: KindMismatch, Pos: 2, Name: "port", Expected: Int, Got: String, Token: "x", Err: errNotKind}, `token 2: port expects Int, got String "x"`},
+		{TokenError{Reason: LiteralMismatch, Pos: 0, Literal: "deploy", Token: "depoly", Suggest: "deploy"}, "token 0: expected \"deploy\", got \"depoly\", did you mean `deploy`?"},
+	}
+	for _, tt := range tests {
+		if got := tt.te.Error(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestVarTypeOfMissingToken(t *testing.T) {
+	in, _ := MustCompile("get ${x:Int}").MatchString("get")
+	if k := in.Get("x").Type(); k != reflect.Invalid {
+		t.Errorf("Type of a missing var is %v, want Invalid", k)
+	}
+	in, _ = MustCompile("get ${x:Int}").MatchString("get 5")
+	if k := in.Get("x").Type(); k != reflect.Int {
+		t.Errorf("Type is %v, want Int", k)
+	}
+}
@@ -17,6 +17,9 @@ func main() {
 			if _, er := inp.Read("${command:String}: ${args:Int}, name:${n:String}", strings.NewReader(in)); er == nil {
 
 				fmt.Println(json.Encode(inp.All(), true).String())
+			} else {
+				fmt.Fprintln(os.Stderr, er)
+				os.Exit(1)
 			}
 		}
 	}
@@ -0,0 +1,140 @@
+package input
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/checker"
+	"github.com/antonmedv/expr/conf"
+	"github.com/antonmedv/expr/parser"
+)
+
+// DefaultExprBudget is the node budget WithExpr uses when given 0.
+const DefaultExprBudget = 64
+
+// WithExpr lets tokens that are not literals be evaluated as expr
+// expressions, so `1+1` reads as 2. Only operators, conditionals, array and
+// map literals and the names given to WithEnv are allowed; builtins,
+// methods, closures, regexp matches and ranges are rejected, and so is any
+// expression of more than budget nodes. An expression must type check as
+// the Kind of its placeholder. A token that is rejected or fails to
+// evaluate is kept as a string, as it is without WithExpr.
+func WithExpr(budget int) Option {
+	return func(p *Pattern) {
+		if budget <= 0 {
+			budget = DefaultExprBudget
+		}
+		if p.expr == nil {
+			p.expr = &exprConfig{}
+		}
+		p.expr.budget = budget
+	}
+}
+
+// WithEnv gives expressions constants and helper functions to use, so that
+// `scale ${factor:Float}` accepts `scale base*2`. The vars captured earlier
+// in the same line are visible too, under their placeholder names. It turns
+// on WithExpr with DefaultExprBudget when that was not given.
+func WithEnv(env map[string]any) Option {
+	return func(p *Pattern) {
+		if p.expr == nil {
+			p.expr = &exprConfig{budget: DefaultExprBudget}
+		}
+		p.expr.env = env
+	}
+}
+
+type exprConfig struct {
+	budget int
+	env    map[string]any
+}
+
+// eval evaluates a token that ParseLiteral rejected, checking that its type
+// suits Kind k before running it.
+func (c *exprConfig) eval(raw string, k Kind, env map[string]any) (v any, err error) {
+	tree, err := parser.Parse(raw)
+	if err != nil {
+		return
+	}
+	guard := &exprGuard{budget: c.budget, env: env}
+	if ast.Walk(&tree.Node, guard); guard.err != nil {
+		err = guard.err
+		return
+	}
+	typ, err := checker.Check(tree, conf.New(env))
+	if err != nil {
+		return
+	}
+	if !kindAllowsType(k, typ) {
+		err = fmt.Errorf("input: %q is a %s, not of kind %s", raw, typ, KindString(k))
+		return
+	}
+	program, err := expr.Compile(raw, expr.Env(env))
+	if err != nil {
+		return
+	}
+	v, err = expr.Run(program, env)
+	return
+}
+
+// kindAllowsType reports whether an expression of type t may fill a
+// placeholder of Kind k. Interface types are let through and the value is
+// checked once it is known.
+func kindAllowsType(k Kind, t reflect.Type) (ok bool) {
+	switch k {
+	case Int, Uint:
+		ok = isInteger(t)
+	case Float:
+		ok = isFloat(t)
+	case String:
+		ok = isString(t)
+	case Bool:
+		ok = isBool(t)
+	case Array:
+		ok = isArray(t)
+	case Map:
+		ok = isMap(t)
+	default:
+		ok = true
+	}
+	return
+}
+
+// exprGuard walks an expression and rejects the nodes WithExpr does not
+// allow, counting them against the budget.
+type exprGuard struct {
+	budget int
+	nodes  int
+	env    map[string]any
+	err    error
+}
+
+func (g *exprGuard) Enter(node *ast.Node) {
+	if g.err != nil {
+		return
+	}
+	if g.nodes++; g.nodes > g.budget {
+		g.err = fmt.Errorf("input: expression exceeds the budget of %d nodes", g.budget)
+		return
+	}
+	switch n := (*node).(type) {
+	case *ast.FunctionNode:
+		if fn, ok := g.env[n.Name]; !ok || fn == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+			g.err = fmt.Errorf("input: unknown function %q", n.Name)
+		}
+	case *ast.MethodNode, *ast.BuiltinNode, *ast.ClosureNode, *ast.PointerNode, *ast.MatchesNode:
+		g.err = fmt.Errorf("input: %T is not allowed in expressions", n)
+	case *ast.IdentifierNode:
+		if _, ok := g.env[n.Value]; !ok {
+			g.err = fmt.Errorf("input: unknown name %q", n.Value)
+		}
+	case *ast.BinaryNode:
+		if n.Operator == ".." {
+			g.err = fmt.Errorf("input: ranges are not allowed in expressions")
+		}
+	}
+}
+
+func (g *exprGuard) Exit(node *ast.Node) {}
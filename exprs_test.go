@@ -0,0 +1,71 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExprEnv(t *testing.T) {
+	env := map[string]any{
+		"base":   2.5,
+		"double": func(x float64) float64 { return x * 2 },
+		"f":      nil,
+	}
+	p := MustCompile("scale ${factor:Float}", WithEnv(env))
+	tests := []struct {
+		line string
+		want any
+		ok   bool
+	}{
+		{"scale 1.5", 1.5, true},
+		{"scale base*2", 5.0, true},
+		{"scale double(base)", 5.0, true},
+		{"scale f(1)", nil, false},
+		{"scale missing*2", nil, false},
+		{"scale base>1", nil, false},
+	}
+	for _, tt := range tests {
+		in, err := p.MatchString(tt.line)
+		if (err == nil) != tt.ok {
+			t.Errorf("%q: got error %v", tt.line, err)
+			continue
+		}
+		if got := in.Get("factor").Value; tt.ok && got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestExprSeesEarlierVars(t *testing.T) {
+	p := MustCompile("box ${w:Int} ${h:Int}", WithEnv(map[string]any{}))
+	in, err := p.MatchString("box 3 w*2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := in.Get("h").Value; got != 6 {
+		t.Errorf("h is %v, want 6", got)
+	}
+	in, _ = p.MatchEnv("box k 1", map[string]any{"k": 4})
+	if got := in.Get("w").Value; got != 4 {
+		t.Errorf("w is %v, want 4 from MatchEnv", got)
+	}
+}
+
+func TestExprRejected(t *testing.T) {
+	p := MustCompile("eval ${x}", WithExpr(4))
+	tests := []struct {
+		line string
+		want any
+	}{
+		{"eval 1+1", 2},
+		{"eval 1+2+3+4", "1+2+3+4"},
+		{"eval foo(1)", "foo(1)"},
+		{"eval 1..3", "1..3"},
+	}
+	for _, tt := range tests {
+		in, _ := p.MatchString(tt.line)
+		if got := in.Get("x").Value; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %#v, want %#v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithFlags lets the placeholders of the Pattern also be filled by flags
+// anywhere in the line: `--name=value`, `--name value`, `name=value`, and
+// `-n value` for a one-letter name or a short flag set WithShortFlag. A
+// bare `--name` sets a Bool placeholder to true; `--name=false` clears it.
+// A variadic placeholder, or one in a repeated group, takes every flag
+// naming it.
+//
+// The tokens left once the flags are taken out are matched by position
+// against the placeholders no flag filled. Literal words of the format are
+// never read as flags, and neither is `name=value` when name is part of a
+// compound word or the token has the shape of one, like `k=1` for
+// `${k}=${v}`. `--` ends the flags: every token after it is positional. A dashed token naming no placeholder is an UnknownFlag, and a
+// placeholder named by two flags a RepeatedFlag.
+func WithFlags() Option {
+	return func(p *Pattern) {
+		p.flags = true
+	}
+}
+
+// WithShortFlag lets `-short value` fill the placeholder called name, and
+// implies WithFlags. Compile fails when the format has no such placeholder.
+func WithShortFlag(short rune, name string) Option {
+	return func(p *Pattern) {
+		p.flags = true
+		if p.shorts == nil {
+			p.shorts = map[rune]string{}
+		}
+		p.shorts[short] = name
+	}
+}
+
+// flagValue is a placeholder filled by flags: the tokens given to it and
+// their positions in the line.
+type flagValue struct {
+	spec *varSpec
+	raws []string
+	at   []int
+}
+
+// flagSet is a line with its flags taken out. rest are the positional
+// tokens left, at their positions in the line, n the number of tokens in
+// the line and glue the tokens of the line glued as split does.
+type flagSet struct {
+	vals  []*flagValue
+	rest  []string
+	at    []int
+	n     int
+	glue  []string
+	errs  []*TokenError
+	slots []SlotScore
+}
+
+// positional is the flagSet of a line read without flags.
+func positional(raws, glue []string) (fs *flagSet) {
+	fs = &flagSet{rest: raws, at: make([]int, len(raws)), n: len(raws), glue: glue}
+	for x := range raws {
+		fs.at[x] = x
+	}
+	return
+}
+
+// filled is the set of placeholders filled by flags.
+func (fs *flagSet) filled() (set map[*varSpec]bool) {
+	set = make(map[*varSpec]bool, len(fs.vals))
+	for _, fv := range fs.vals {
+		set[fv.spec] = true
+	}
+	return
+}
+
+// takeFlags takes the flags of p out of raws, glued as glue. Without
+// WithFlags, every token is positional.
+func (p *Pattern) takeFlags(raws, glue []string) (fs *flagSet) {
+	if !p.flags {
+		fs = positional(raws, glue)
+		return
+	}
+	fs = &flagSet{n: len(raws), glue: glue}
+	w := p.scoreWeights()
+	extra := math.Max(w.Literal, w.Kind)
+	named := map[string]*flagValue{}
+	for x := 0; x < len(raws); x++ {
+		raw := raws[x]
+		if raw == "--" {
+			for x++; x < len(raws); x++ {
+				fs.rest, fs.at = append(fs.rest, raws[x]), append(fs.at, x)
+			}
+			break
+		}
+		s, val, hasVal, isFlag := p.flag(raw)
+		if !isFlag {
+			fs.rest, fs.at = append(fs.rest, raw), append(fs.at, x)
+			continue
+		}
+		if s == nil {
+			fs.errs = append(fs.errs, &TokenError{Reason: UnknownFlag, Pos: x, Token: raw})
+			fs.slots = append(fs.slots, SlotScore{Pos: x, Token: raw, Max: extra})
+			continue
+		}
+		fv := named[s.name]
+		if fv != nil && !s.repeated {
+			fs.errs = append(fs.errs, &TokenError{Reason: RepeatedFlag, Pos: x, Name: s.name, Expected: s.kind, Token: raw})
+			fs.slots = append(fs.slots, SlotScore{Pos: x, Token: raw, Name: s.name, Max: extra})
+			if !hasVal && s.kind != Bool && x+1 < len(raws) {
+				x++
+			}
+			continue
+		}
+		if fv == nil {
+			fv = &flagValue{spec: s}
+			named[s.name] = fv
+			fs.vals = append(fs.vals, fv)
+		}
+		switch {
+		case hasVal:
+		case s.kind == Bool && !s.repeated:
+			val = "true"
+		case x+1 < len(raws) && glue[x+1] != "" && s.takesUnit():
+			fv.raws, fv.at = append(fv.raws, glue[x+1]), append(fv.at, x+1)
+			x += 2
+			continue
+		case x+1 < len(raws):
+			x++
+			val = raws[x]
+		default:
+			fs.errs = append(fs.errs, &TokenError{Reason: MissingToken, Pos: x + 1, Name: s.name, Expected: s.kind})
+			fs.slots = append(fs.slots, SlotScore{Pos: -1, Name: s.name, Max: w.slotMax(s)})
+			continue
+		}
+		fv.raws, fv.at = append(fv.raws, val), append(fv.at, x)
+	}
+	return
+}
+
+// flag reads raw as a flag of p. isFlag is false for a token that is not
+// written as a flag, and s is nil for a flag naming no placeholder. A
+// negative number is not a flag, and neither is `name=value` when name is
+// not a placeholder or raw may be a compound word.
+func (p *Pattern) flag(raw string) (s *varSpec, val string, hasVal, isFlag bool) {
+	for _, e := range p.elems {
+		if e.spec == nil && e.literal == raw {
+			return
+		}
+	}
+	var name string
+	switch {
+	case strings.HasPrefix(raw, "--") && len(raw) > 2:
+		name, val, hasVal = strings.Cut(raw[2:], "=")
+		s, isFlag = p.spec(name), true
+	case len(raw) > 1 && raw[0] == '-' && unicode.IsLetter(rune(raw[1])):
+		name, val, hasVal = strings.Cut(raw[1:], "=")
+		if r, size := utf8.DecodeRuneInString(name); size == len(name) {
+			if long, ok := p.shorts[r]; ok {
+				name = long
+			}
+			s = p.spec(name)
+		}
+		isFlag = true
+	default:
+		name, val, hasVal = strings.Cut(raw, "=")
+		if hasVal && !strings.HasPrefix(val, "=") && !p.compound(name, raw) {
+			s = p.spec(name)
+			isFlag = s != nil
+		}
+	}
+	return
+}
+
+// compound reports whether raw may be a compound word of p: name is one of
+// its placeholders, or raw has its shape.
+func (p *Pattern) compound(name, raw string) bool {
+	for _, o := range p.prog {
+		if o.code != opWord || len(o.n.word) < 2 {
+			continue
+		}
+		for _, e := range o.n.word {
+			if e.spec != nil && e.spec.name == name {
+				return true
+			}
+		}
+		if shaped(o.n.word, raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// shaped reports whether s has the literal parts of word in order, with at
+// least one rune for each placeholder.
+func shaped(word []element, s string) bool {
+	if len(word) == 0 {
+		return s == ""
+	}
+	if e := word[0]; e.spec == nil {
+		return strings.HasPrefix(s, e.literal) && shaped(word[1:], s[len(e.literal):])
+	}
+	for end := 1; end <= len(s); end++ {
+		if (end == len(s) || utf8.RuneStart(s[end])) && shaped(word[1:], s[end:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// spec returns the placeholder called name, or nil.
+func (p *Pattern) spec(name string) (s *varSpec) {
+	for _, e := range p.elems {
+		if e.spec != nil && e.spec.name == name {
+			s = e.spec
+			return
+		}
+	}
+	return
+}
+
+// checkShorts reports short flags set WithShortFlag for an unknown
+// placeholder, or that hide a one-letter placeholder.
+func (p *Pattern) checkShorts() (err error) {
+	for r, name := range p.shorts {
+		if p.spec(name) == nil {
+			err = fmt.Errorf("input: short flag -%c for unknown placeholder %q", r, name)
+			return
+		}
+		if s := p.spec(string(r)); s != nil && s.name != name {
+			err = fmt.Errorf("input: short flag -%c for %q hides placeholder %q", r, name, s.name)
+			return
+		}
+	}
+	return
+}
+
+// place moves the positions a got from matching the positional tokens of
+// fs back to the line.
+func (fs *flagSet) place(a *alignment) {
+	at := func(pos int) int {
+		switch {
+		case pos < 0:
+		case pos < len(fs.at):
+			pos = fs.at[pos]
+		default:
+			pos += fs.n - len(fs.at)
+		}
+		return pos
+	}
+	for _, te := range a.errs {
+		te.Pos = at(te.Pos)
+	}
+	for x := range a.report.Slots {
+		a.report.Slots[x].Pos = at(a.report.Slots[x].Pos)
+	}
+	for x := range a.suggestions {
+		a.suggestions[x].Pos = at(a.suggestions[x].Pos)
+	}
+}
@@ -0,0 +1,107 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlags(t *testing.T) {
+	p := MustCompile("deploy ${env} ${port:Int=80} ${verbose:Bool=false} ${tags?...:String}", WithShortFlag('p', "port"))
+	tests := []struct {
+		line string
+		want map[string]any
+	}{
+		{"deploy prod", map[string]any{"env": "prod", "port": 80, "verbose": false, "tags": []string{}}},
+		{"deploy --port=8080 prod", map[string]any{"env": "prod", "port": 8080, "verbose": false, "tags": []string{}}},
+		{"deploy prod --port 8080", map[string]any{"env": "prod", "port": 8080, "verbose": false, "tags": []string{}}},
+		{"deploy -p 8080 prod", map[string]any{"env": "prod", "port": 8080, "verbose": false, "tags": []string{}}},
+		{"deploy port=9 prod", map[string]any{"env": "prod", "port": 9, "verbose": false, "tags": []string{}}},
+		{"deploy --verbose prod", map[string]any{"env": "prod", "port": 80, "verbose": true, "tags": []string{}}},
+		{"deploy prod --verbose=false", map[string]any{"env": "prod", "port": 80, "verbose": false, "tags": []string{}}},
+		{"deploy --tags a --tags b prod", map[string]any{"env": "prod", "port": 80, "verbose": false, "tags": []string{"a", "b"}}},
+		{"deploy --env=dev", map[string]any{"env": "dev", "port": 80, "verbose": false, "tags": []string{}}},
+		{"deploy prod -- --port", map[string]any{"env": "prod", "port": 80, "verbose": false, "tags": []string{"--port"}}},
+		{"deploy prod -5", map[string]any{"env": "prod", "port": -5, "verbose": false, "tags": []string{}}},
+		{"deploy prod a=b", map[string]any{"env": "prod", "port": 80, "verbose": false, "tags": []string{"a=b"}}},
+	}
+	for _, tt := range tests {
+		in, err := p.MatchString(tt.line)
+		if err != nil || !reflect.DeepEqual(in.All(), tt.want) {
+			t.Errorf("%q: got %v, %v, want %v", tt.line, in.All(), err, tt.want)
+		}
+	}
+	in, err := MustCompile("set ${mode} deploy", WithFlags()).MatchString("set deploy deploy")
+	if err != nil || in.Get("mode").Value != "deploy" {
+		t.Errorf("a literal word was read as a flag: %v, %v", in.All(), err)
+	}
+}
+
+func TestFlagErrors(t *testing.T) {
+	p := MustCompile("deploy ${env} ${port:Int=80}", WithFlags())
+	tests := []struct {
+		line   string
+		reason Reason
+		err    string
+	}{
+		{"deploy prod --nope", UnknownFlag, `token 2: unknown flag "--nope"`},
+		{"deploy --port 1 --port 2 prod", RepeatedFlag, "token 3: port is given more than once"},
+		{"deploy prod --port", MissingToken, "token 3: missing port (Int)"},
+		{"deploy prod --port x", KindMismatch, `token 3: port expects Int, got String "x"`},
+		{"deploy --env=prod 1 x", ExtraToken, `token 3: unexpected "x"`},
+	}
+	for _, tt := range tests {
+		_, err := p.MatchString(tt.line)
+		if me, ok := err.(*MatchError); !ok || me.Errors[0].Reason != tt.reason || me.Errors[0].Error() != tt.err {
+			t.Errorf("%q: got error %v, want %s", tt.line, err, tt.err)
+		}
+	}
+	compileTests := []struct {
+		format string
+		short  rune
+		name   string
+		err    string
+	}{
+		{"x ${a}", 'b', "zz", `input: short flag -b for unknown placeholder "zz"`},
+		{"x ${a} ${b}", 'b', "a", `input: short flag -b for "a" hides placeholder "b"`},
+	}
+	for _, tt := range compileTests {
+		if _, err := Compile(tt.format, WithShortFlag(tt.short, tt.name)); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got error %v, want %s", tt.format, err, tt.err)
+		}
+	}
+}
+
+func TestFlagsInRepeatedGroup(t *testing.T) {
+	p := MustCompile("tag (${l:String})+", WithFlags())
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"tag x", []string{"x"}},
+		{"tag --l x", []string{"x"}},
+		{"tag --l x --l y", []string{"x", "y"}},
+	}
+	for _, tt := range tests {
+		in, err := p.MatchString(tt.line)
+		if err != nil || !reflect.DeepEqual(in.Get("l").Value, tt.want) {
+			t.Errorf("%q: got %#v, %v, want %#v", tt.line, in.Get("l").Value, err, tt.want)
+		}
+	}
+}
+
+func TestFlagsCompound(t *testing.T) {
+	tests := []struct {
+		format, line string
+		want         map[string]any
+	}{
+		{"set (${k:String}=${v:Int})+", "set k=1", map[string]any{"k": []string{"k"}, "v": []int{1}}},
+		{"set ${k:String}@${v:Int}", "set k=a@1", map[string]any{"k": "k=a", "v": 1}},
+		{"set ${a:String} ${k:String}@${v:Int}", "set a=x k@1", map[string]any{"a": "x", "k": "k", "v": 1}},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format, WithFlags()).MatchString(tt.line)
+		if err != nil || !reflect.DeepEqual(in.All(), tt.want) {
+			t.Errorf("%q: got %v, %v, want %v", tt.line, in.All(), err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package input
+
+import "fmt"
+
+// Suggestion is a literal word that a mistyped token probably meant.
+type Suggestion struct {
+	// Pos is the position of the token in the input.
+	Pos      int
+	Token    string
+	Literal  string
+	Distance int
+}
+
+func (s Suggestion) String() string {
+	return fmt.Sprintf("did you mean `%s`?", s.Literal)
+}
+
+// fuzzyScore is the partial score of a token dist edits away from lit.
+func fuzzyScore(token, lit string, dist int) (score float64) {
+	n := len([]rune(lit))
+	if m := len([]rune(token)); m > n {
+		n = m
+	}
+	score = 1 - float64(dist)/float64(n)
+	if score < 0 {
+		score = 0
+	}
+	return
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// the number of rune insertions, deletions, substitutions and swaps of
+// neighbouring runes that turn a into b.
+func editDistance(a, b string) (dist int) {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for y := range prev {
+		prev[y] = y
+	}
+	for x := 1; x <= len(ra); x++ {
+		cur[0] = x
+		for y := 1; y <= len(rb); y++ {
+			cost := 1
+			if ra[x-1] == rb[y-1] {
+				cost = 0
+			}
+			cur[y] = minInt(minInt(prev[y]+1, cur[y-1]+1), prev[y-1]+cost)
+			if x > 1 && y > 1 && ra[x-1] == rb[y-2] && ra[x-2] == rb[y-1] {
+				cur[y] = minInt(cur[y], prev2[y-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	dist = prev[len(rb)]
+	return
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+package input
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"deploy", "deploy", 0},
+		{"depoly", "deploy", 1},
+		{"deply", "deploy", 1},
+		{"deployy", "deploy", 1},
+		{"dxploy", "deploy", 1},
+		{"dxxxoy", "deploy", 3},
+		{"", "abc", 3},
+		{"ünï", "ïnü", 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFuzzyLiterals(t *testing.T) {
+	tests := []struct {
+		line    string
+		maxDist int
+		suggest string
+		score   float64
+	}{
+		{"deploy prod", 2, "", 1},
+		{"depoly prod", 2, "deploy", (1 + 5.0/6) / 2},
+		{"depoly prod", 0, "", 0.5},
+		{"dxxxoy prod", 2, "", 0.5},
+		{"dxxxoy prod", 3, "deploy", 0.75},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile("deploy ${env}", WithFuzzy(tt.maxDist)).MatchString(tt.line)
+		var got string
+		if s := in.Suggestions(); len(s) == 1 {
+			got = s[0].Literal
+			if s[0].Pos != 0 || s[0].Token != tt.line[:6] || s[0].String() != "did you mean `deploy`?" {
+				t.Errorf("%q: bad suggestion %+v", tt.line, s[0])
+			}
+		}
+		if got != tt.suggest || math.Abs(in.Score()-tt.score) > 1e-9 {
+			t.Errorf("%q with %d: got suggestion %q and score %v, want %q and %v", tt.line, tt.maxDist, got, in.Score(), tt.suggest, tt.score)
+		}
+		me, ok := err.(*MatchError)
+		if tt.line[:6] != "deploy" && (!ok || me.Errors[0].Reason != LiteralMismatch || me.Errors[0].Suggest != tt.suggest) {
+			t.Errorf("%q: got error %v", tt.line, err)
+		}
+	}
+}
@@ -4,13 +4,17 @@ go 1.18
 
 replace github.com/hyprstereo/input/internal => ./internal
 
+require (
+	github.com/antonmedv/expr v1.9.0
+	github.com/hyprstereo/go-dao v0.1.5
+	golang.org/x/text v0.3.4
+)
+
 require (
 	bitbucket.org/creachadair/shell v0.0.7 // indirect
-	github.com/antonmedv/expr v1.9.0 // indirect
 	github.com/goccy/go-json v0.9.7 // indirect
 	github.com/golang/protobuf v1.4.3 // indirect
 	github.com/gorhill/cronexpr v0.0.0-20180427100037-88b0669f7d75 // indirect
-	github.com/hyprstereo/go-dao v0.1.5 // indirect
 	github.com/iancoleman/strcase v0.2.0 // indirect
 	github.com/itchyny/gojq v0.12.8 // indirect
 	github.com/itchyny/timefmt-go v0.1.3 // indirect
@@ -28,7 +32,6 @@ require (
 	github.com/x-mod/routine v1.3.3 // indirect
 	github.com/x-mod/sigtrap v0.1.1 // indirect
 	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
-	golang.org/x/text v0.3.4 // indirect
 	google.golang.org/genproto v0.0.0-20210226172003-ab064af71705 // indirect
 	google.golang.org/grpc v1.35.0 // indirect
 	google.golang.org/protobuf v1.25.0 // indirect
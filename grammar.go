@@ -0,0 +1,791 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type nodeKind uint8
+
+const (
+	// wordNode takes one token: a literal word, a placeholder, or a
+	// compound word of both like `${k}=${v}`.
+	wordNode nodeKind = iota
+	seqNode
+	altNode
+	optNode
+	repNode
+)
+
+// node is a part of the grammar of a format.
+type node struct {
+	kind nodeKind
+	// text is the word as errors show it, placeholders written `<name>`.
+	text string
+	word []element
+	subs []*node
+	// min is the number of times a repNode must match: 0 or 1.
+	min int
+}
+
+// formatParser builds the grammar of a format and the flat list of its
+// elements. grammar is set once the format turns out to have groups or
+// compound words.
+type formatParser struct {
+	p       *Pattern
+	seen    map[string]bool
+	elems   []element
+	grammar bool
+	reps    int
+}
+
+// seq parses the words of text in order.
+func (fp *formatParser) seq(text string) (n *node, err error) {
+	n = &node{kind: seqNode}
+	for _, w := range fp.p.separators().split(text) {
+		if w == "" {
+			err = fmt.Errorf("input: empty word in %q", text)
+			return
+		}
+		var sub *node
+		if sub, err = fp.word(w); err != nil {
+			return
+		}
+		n.subs = append(n.subs, sub)
+	}
+	return
+}
+
+func (fp *formatParser) word(w string) (n *node, err error) {
+	switch {
+	case (w[0] == '(' || w[0] == '[') && groupEnd(w) < 0:
+		err = fmt.Errorf("input: unbalanced %q in %s", w[0], w)
+	case w[0] == '(' || w[0] == '[':
+		n, err = fp.group(w)
+	case isVar(w) && placeholderEnd(w) == len(w):
+		var s *varSpec
+		if s, err = fp.spec(w, true); err != nil {
+			return
+		}
+		n = &node{kind: wordNode, text: "<" + s.name + ">", word: []element{{spec: s}}}
+		if s.variadic {
+			n = &node{kind: repNode, subs: []*node{n}, min: 1}
+			if s.optional {
+				n.min = 0
+			}
+		}
+	case strings.Contains(w, "${"):
+		fp.grammar = true
+		n = &node{kind: wordNode}
+		for rest := w; rest != ""; {
+			start := strings.Index(rest, "${")
+			if start < 0 {
+				start = len(rest)
+			}
+			if start > 0 {
+				n.word = append(n.word, element{literal: rest[:start]})
+				n.text += rest[:start]
+				rest = rest[start:]
+				continue
+			}
+			end := placeholderEnd(rest)
+			var s *varSpec
+			if s, err = fp.spec(rest[:end], false); err != nil {
+				return
+			}
+			n.word = append(n.word, element{spec: s})
+			n.text += "<" + s.name + ">"
+			rest = rest[end:]
+		}
+	default:
+		n = &node{kind: wordNode, text: w, word: []element{{literal: w}}}
+		fp.elems = append(fp.elems, element{literal: w})
+	}
+	return
+}
+
+// group parses `(a|b)`, `[a]`, `(a)?`, `(a)+` and `(a)*`.
+func (fp *formatParser) group(w string) (n *node, err error) {
+	fp.grammar = true
+	end := groupEnd(w)
+	inner, suffix := w[1:end], w[end+1:]
+	if w[0] == '[' && suffix != "" || w[0] == '(' && suffix != "" && suffix != "?" && suffix != "+" && suffix != "*" {
+		err = fmt.Errorf("input: unexpected %q after group %s", suffix, w[:end+1])
+		return
+	}
+	repeated := suffix == "+" || suffix == "*"
+	if repeated {
+		fp.reps++
+		defer func() { fp.reps-- }()
+	}
+	alts := splitAlts(inner)
+	n = &node{kind: altNode}
+	for _, alt := range alts {
+		var sub *node
+		if sub, err = fp.seq(alt); err != nil {
+			return
+		}
+		if len(sub.subs) == 0 {
+			err = fmt.Errorf("input: empty alternative in %s", w[:end+1])
+			return
+		}
+		n.subs = append(n.subs, sub)
+	}
+	if len(n.subs) == 1 {
+		n = n.subs[0]
+	}
+	switch {
+	case w[0] == '[' || suffix == "?":
+		n = &node{kind: optNode, subs: []*node{n}}
+	case suffix == "+":
+		n = &node{kind: repNode, subs: []*node{n}, min: 1}
+	case suffix == "*":
+		n = &node{kind: repNode, subs: []*node{n}}
+	}
+	return
+}
+
+// spec parses a placeholder of the format. whole is false for one that
+// shares its word with other text.
+func (fp *formatParser) spec(w string, whole bool) (s *varSpec, err error) {
+	p := fp.p
+	if s, err = parseVar(w, p.nvars, p.coercion); err != nil {
+		return
+	}
+	switch {
+	case fp.seen[s.name]:
+		s, err = nil, fmt.Errorf("input: duplicate placeholder %q", s.name)
+	case s.variadic && !whole:
+		s, err = nil, fmt.Errorf("input: variadic placeholder %s must be a whole word", w)
+	case s.variadic && p.variadic:
+		s, err = nil, fmt.Errorf("input: more than one variadic placeholder in %q", p.format)
+	}
+	if err != nil {
+		return
+	}
+	fp.seen[s.name] = true
+	s.repeated = s.variadic || fp.reps > 0
+	p.variadic = p.variadic || s.variadic
+	p.units = p.units || s.takesUnit()
+	p.nvars++
+	fp.elems = append(fp.elems, element{spec: s})
+	return
+}
+
+// checkReps reports a repeated group that may take no token, which would
+// repeat forever, and one that only repeats another: `((a)+)+` has
+// countless ways to split the same tokens between its turns.
+func (n *node) checkReps() (err error) {
+	if n.kind == repNode && n.subs[0].nullable() {
+		err = fmt.Errorf("input: repeated group around %s may take no token", n.subs[0].String())
+		return
+	}
+	if n.kind == repNode {
+		body := n.subs[0]
+		for body.kind == seqNode && len(body.subs) == 1 {
+			body = body.subs[0]
+		}
+		if body.kind == repNode {
+			err = fmt.Errorf("input: repeated group around %s repeats another", body.String())
+			return
+		}
+	}
+	for _, sub := range n.subs {
+		if err = sub.checkReps(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// nullable reports whether n can match without taking a token.
+func (n *node) nullable() (ok bool) {
+	switch n.kind {
+	case wordNode:
+		s := n.word[0].spec
+		ok = len(n.word) == 1 && s != nil && s.optional && !s.repeated
+	case seqNode:
+		ok = true
+		for _, sub := range n.subs {
+			if ok = sub.nullable(); !ok {
+				break
+			}
+		}
+	case altNode:
+		for _, sub := range n.subs {
+			if ok = sub.nullable(); ok {
+				break
+			}
+		}
+	case optNode:
+		ok = true
+	case repNode:
+		ok = n.min == 0 || n.subs[0].nullable()
+	}
+	return
+}
+
+// String writes n back as a format, placeholders written `<name>`.
+func (n *node) String() (str string) {
+	switch n.kind {
+	case wordNode:
+		str = n.text
+	case seqNode, altNode:
+		words := make([]string, len(n.subs))
+		for x, sub := range n.subs {
+			words[x] = sub.String()
+		}
+		if n.kind == seqNode {
+			str = strings.Join(words, " ")
+		} else {
+			str = "(" + strings.Join(words, "|") + ")"
+		}
+	case optNode:
+		str = "[" + n.subs[0].String() + "]"
+	case repNode:
+		str = "(" + n.subs[0].String() + ")*"
+		if n.min > 0 {
+			str = "(" + n.subs[0].String() + ")+"
+		}
+	}
+	return
+}
+
+// groupEnd returns the offset of the bracket closing the one that starts
+// w, or -1.
+func groupEnd(w string) (end int) {
+	lvl := 0
+	for end = 0; end < len(w); end++ {
+		switch w[end] {
+		case '(', '[', '{':
+			lvl++
+		case ')', ']', '}':
+			if lvl--; lvl == 0 {
+				return
+			}
+		}
+	}
+	end = -1
+	return
+}
+
+// splitAlts splits the inside of a group at the `|` that are not nested in
+// brackets or placeholders.
+func splitAlts(text string) (alts []string) {
+	lvl, start := 0, 0
+	for x := 0; x < len(text); x++ {
+		switch text[x] {
+		case '(', '[', '{':
+			lvl++
+		case ')', ']', '}':
+			lvl--
+		case '|':
+			if lvl == 0 {
+				alts = append(alts, text[start:x])
+				start = x + 1
+			}
+		}
+	}
+	alts = append(alts, text[start:])
+	return
+}
+
+// capture is a value taken by a placeholder from a token, or from part of
+// one for a compound word.
+type capture struct {
+	spec    *varSpec
+	pos     int
+	raw     string
+	val     any
+	coerced bool
+}
+
+type opCode uint8
+
+const (
+	// opWord takes a token for the word n.
+	opWord opCode = iota
+	// opSplit goes on at x, and also at y when that way does better.
+	opSplit
+	opJump
+	opMatch
+)
+
+// op is an instruction of the program a grammar is compiled to.
+type op struct {
+	code opCode
+	n    *node
+	x, y int
+}
+
+// compile turns the grammar n into the program run by matcher. An optional
+// group or placeholder splits between taking its words and leaving them
+// out, a repetition between one more turn and stopping, and an alternative
+// between its first branch and the rest; the first way is tried first.
+func compile(n *node) (prog []op) {
+	prog = append(emit(n, nil), op{code: opMatch})
+	return
+}
+
+func emit(n *node, prog []op) []op {
+	switch n.kind {
+	case wordNode:
+		if s := n.word[0].spec; len(n.word) == 1 && s != nil && s.optional && !s.repeated {
+			prog = append(prog, op{code: opSplit, x: len(prog) + 1, y: len(prog) + 2})
+		}
+		prog = append(prog, op{code: opWord, n: n})
+	case seqNode:
+		for _, sub := range n.subs {
+			prog = emit(sub, prog)
+		}
+	case altNode:
+		var jumps []int
+		for x, sub := range n.subs {
+			if x == len(n.subs)-1 {
+				prog = emit(sub, prog)
+				break
+			}
+			split := len(prog)
+			prog = emit(sub, append(prog, op{code: opSplit, x: split + 1}))
+			jumps = append(jumps, len(prog))
+			prog = append(prog, op{code: opJump})
+			prog[split].y = len(prog)
+		}
+		for _, j := range jumps {
+			prog[j].x = len(prog)
+		}
+	case optNode:
+		split := len(prog)
+		prog = emit(n.subs[0], append(prog, op{code: opSplit, x: split + 1}))
+		prog[split].y = len(prog)
+	case repNode:
+		start := len(prog)
+		if n.min > 0 {
+			prog = emit(n.subs[0], prog)
+			prog = append(prog, op{code: opSplit, x: start, y: len(prog) + 1})
+			break
+		}
+		prog = emit(n.subs[0], append(prog, op{code: opSplit, x: start + 1}))
+		prog = append(prog, op{code: opJump, x: start})
+		prog[start].y = len(prog)
+	}
+	return prog
+}
+
+// trail is a way through the grammar, linked from its last step back so
+// that ways with the same start share it.
+type trail struct {
+	prev  *trail
+	pos   int
+	n     *node
+	caps  []capture
+	slots []SlotScore
+	te    *TokenError
+}
+
+// thread is a way through the grammar up to some instruction: the steps it
+// took, the errors it met and the points its slots could not earn.
+type thread struct {
+	last *trail
+	errs int
+	lost float64
+}
+
+// better reports whether th has fewer errors than o, or as many and fewer
+// points lost.
+func (th *thread) better(o *thread) bool {
+	return th.errs < o.errs || th.errs == o.errs && th.lost < o.lost
+}
+
+// then is th with one more step.
+func (th *thread) then(tr *trail) (next *thread) {
+	tr.prev = th.last
+	next = &thread{last: tr, errs: th.errs, lost: th.lost}
+	if tr.te != nil {
+		next.errs++
+	}
+	for _, ss := range tr.slots {
+		next.lost += ss.Max - ss.Points
+	}
+	return
+}
+
+// threads are the ways that reached each instruction after the same
+// tokens, in the order the instructions were first reached.
+type threads struct {
+	at    []*thread
+	order []int
+}
+
+// matcher matches tokens against the program of a Pattern. Every way
+// through the grammar is followed at once, one token at a time, and only
+// the best way to each instruction is kept, so a match takes time in
+// proportion to the tokens times the size of the grammar. A word may take
+// a token it does not accept at the cost of an error, and once the tokens
+// run out the words left are missing ones, so the best way is also the one
+// that explains a bad line with the fewest errors.
+type matcher struct {
+	a    *alignment
+	prog []op
+	raws []string
+	// glue[t], when not empty, is the tokens at t and t+1 read together,
+	// for a Bytes or Duration placeholder to take as one.
+	glue []string
+	set  map[*varSpec]bool
+	// exact only follows the ways that take each token without an error,
+	// to tell which words may come next.
+	exact bool
+}
+
+// run returns the best way through all of the tokens, with the position
+// of the first token it leaves over, and the ways that took every token.
+// Of two ways as good, the one leaving fewer tokens over wins.
+func (m *matcher) run() (best *thread, from int, end *threads) {
+	end = m.list()
+	m.add(end, 0, 0, &thread{})
+	after := m.list()
+	extra := math.Max(m.a.w.Literal, m.a.w.Kind)
+	for t := 0; ; t++ {
+		for _, pc := range end.order {
+			if m.prog[pc].code != opMatch || m.exact {
+				continue
+			}
+			th := end.at[pc]
+			left := len(m.raws) - t
+			fin := &thread{last: th.last, errs: th.errs + left, lost: th.lost + extra*float64(left)}
+			if best == nil || !best.better(fin) {
+				best, from = fin, t
+			}
+		}
+		if t == len(m.raws) {
+			return
+		}
+		next := after
+		after = m.list()
+		for _, pc := range end.order {
+			if o := m.prog[pc]; o.code == opWord && !m.filled(o.n) {
+				if th := m.take(end.at[pc], o.n, t); th != nil {
+					m.add(next, pc+1, t+1, th)
+				}
+				if th := m.glued(end.at[pc], o.n, t); th != nil {
+					m.add(after, pc+1, t+2, th)
+				}
+			}
+		}
+		if end = next; len(end.order) == 0 && len(after.order) == 0 {
+			return
+		}
+	}
+}
+
+func (m *matcher) list() *threads {
+	return &threads{at: make([]*thread, len(m.prog))}
+}
+
+// add puts th at the instruction pc of l, unless a better way is there
+// already, and follows it through the instructions that take no token.
+// At the end of the tokens a word may be passed as missing.
+func (m *matcher) add(l *threads, pc, t int, th *thread) {
+	if old := l.at[pc]; old == nil {
+		l.order = append(l.order, pc)
+	} else if !th.better(old) {
+		return
+	}
+	l.at[pc] = th
+	switch o := m.prog[pc]; o.code {
+	case opJump:
+		m.add(l, o.x, t, th)
+	case opSplit:
+		m.add(l, o.x, t, th)
+		m.add(l, o.y, t, th)
+	case opWord:
+		if m.filled(o.n) {
+			m.add(l, pc+1, t, th)
+		} else if t == len(m.raws) && !m.exact {
+			m.add(l, pc+1, t, th.then(m.missing(o.n, t)))
+		}
+	}
+}
+
+// filled reports whether n is a placeholder filled by flags, which takes
+// no token.
+func (m *matcher) filled(n *node) bool {
+	s := n.word[0].spec
+	return len(n.word) == 1 && s != nil && m.set[s]
+}
+
+// take matches the token at t against the word n on the way th. It is nil
+// when the token does not fit and m is exact.
+func (m *matcher) take(th *thread, n *node, t int) (next *thread) {
+	raw := m.raws[t]
+	tr := &trail{pos: t, n: n}
+	w := m.a.w
+	switch e := n.word[0]; {
+	case len(n.word) > 1:
+		if tr.caps, tr.slots = m.pieces(th, n.word, raw, t, nil, nil); tr.caps == nil {
+			tr.te = &TokenError{Reason: LiteralMismatch, Pos: t, Literal: n.text, Token: raw, Got: kindOf(m.a.eval(raw))}
+			tr.slots = nil
+			n.walk(func(s *varSpec) {
+				tr.slots = append(tr.slots, SlotScore{Pos: t, Token: raw, Name: s.name, Max: w.slotMax(s)})
+			})
+		}
+	case e.spec == nil:
+		ss := SlotScore{Pos: t, Token: raw, Literal: e.literal, Max: w.Literal}
+		if raw == e.literal {
+			ss.LiteralHit, ss.Points = true, w.Literal
+		} else {
+			tr.te = &TokenError{Reason: LiteralMismatch, Pos: t, Literal: e.literal, Token: raw, Got: kindOf(m.a.eval(raw))}
+			if p := m.a.p; p.fuzzy > 0 {
+				if d := editDistance(raw, e.literal); d <= p.fuzzy {
+					ss.Points = w.Literal * fuzzyScore(raw, e.literal, d)
+					tr.te.Suggest = e.literal
+				}
+			}
+		}
+		tr.slots = []SlotScore{ss}
+	case raw == "" && e.spec.optional && !e.spec.repeated:
+		// An empty token between two separators that do not collapse
+		// leaves the placeholder out.
+		tr.slots = []SlotScore{{Pos: t, Name: e.spec.name, DefaultUsed: e.spec.hasDef, Points: w.Default, Max: w.Default}}
+	default:
+		c, ss, te := m.a.capture(e.spec, raw, t, m.scope(th))
+		if tr.slots, tr.te = []SlotScore{ss}, te; te == nil {
+			tr.caps = []capture{c}
+		}
+	}
+	if tr.te != nil && m.exact {
+		return
+	}
+	next = th.then(tr)
+	return
+}
+
+// glued matches the tokens at t and t+1 read together against the word n
+// on the way th. It is nil unless n is a Bytes or Duration placeholder
+// that takes them.
+func (m *matcher) glued(th *thread, n *node, t int) (next *thread) {
+	if t >= len(m.glue) || m.glue[t] == "" || len(n.word) > 1 || n.word[0].spec == nil || !n.word[0].spec.takesUnit() {
+		return
+	}
+	c, ss, te := m.a.capture(n.word[0].spec, m.glue[t], t, m.scope(th))
+	if te != nil {
+		return
+	}
+	next = th.then(&trail{pos: t, n: n, caps: []capture{c}, slots: []SlotScore{ss}})
+	return
+}
+
+// missing is the step of a word n found missing at the end of the tokens.
+func (m *matcher) missing(n *node, t int) (tr *trail) {
+	tr = &trail{pos: -1, n: n}
+	w := m.a.w
+	if e := n.word[0]; len(n.word) == 1 && e.spec == nil {
+		tr.te = &TokenError{Reason: MissingToken, Pos: t, Literal: e.literal}
+		tr.slots = []SlotScore{{Pos: -1, Literal: e.literal, Max: w.Literal}}
+		return
+	}
+	tr.te = &TokenError{Reason: MissingToken, Pos: t, Literal: n.text}
+	if s := n.word[0].spec; len(n.word) == 1 {
+		tr.te.Literal, tr.te.Name, tr.te.Expected = "", s.name, s.kind
+	}
+	n.walk(func(s *varSpec) {
+		tr.slots = append(tr.slots, SlotScore{Pos: -1, Name: s.name, Max: w.slotMax(s)})
+	})
+	return
+}
+
+// pieces matches s against the rest of a compound word, giving each
+// placeholder the shortest part of s that lets the rest match. caps is nil
+// when s does not fit.
+func (m *matcher) pieces(th *thread, word []element, s string, t int, caps []capture, slots []SlotScore) ([]capture, []SlotScore) {
+	if len(word) == 0 {
+		if s != "" {
+			return nil, nil
+		}
+		return caps, slots
+	}
+	if e := word[0]; e.spec == nil {
+		if !strings.HasPrefix(s, e.literal) {
+			return nil, nil
+		}
+		return m.pieces(th, word[1:], s[len(e.literal):], t, caps, slots)
+	}
+	for end := 1; end <= len(s); end++ {
+		if end < len(s) && !utf8.RuneStart(s[end]) {
+			continue
+		}
+		if c, ss, te := m.a.capture(word[0].spec, s[:end], t, m.scope(th)); te == nil {
+			out, outSlots := m.pieces(th, word[1:], s[end:], t, append(caps[:len(caps):len(caps)], c), append(slots[:len(slots):len(slots)], ss))
+			if out != nil {
+				return out, outSlots
+			}
+		}
+	}
+	return nil, nil
+}
+
+// capture reads raw at t for s, with expressions seeing scope. te says why
+// s cannot take it, and ss is the slot it makes either way.
+func (a *alignment) capture(s *varSpec, raw string, t int, scope func() map[string]any) (c capture, ss SlotScore, te *TokenError) {
+	w := a.w
+	val, got, coerced, er := s.parse(raw, func(raw string) any { return a.p.evalAs(raw, s.kind, scope) })
+	if er == nil && val == nil && s.repeated {
+		er = errors.New("nil is not allowed here")
+	}
+	if er != nil {
+		te = &TokenError{Reason: KindMismatch, Pos: t, Name: s.name, Expected: s.kind, Token: raw, Got: got, Err: er}
+		ss = SlotScore{Pos: t, Token: raw, Name: s.name, Max: w.slotMax(s)}
+		return
+	}
+	if !s.variadic {
+		er = s.check(val, raw)
+	} else if s.constraint != nil {
+		er = s.constraint.checkToken(val, raw)
+	}
+	if ss = w.hit(s, t, raw, coerced, er); er != nil {
+		te = &TokenError{Reason: ConstraintViolation, Pos: t, Name: s.name, Expected: s.kind, Token: raw, Got: got, Err: er}
+		return
+	}
+	c = capture{spec: s, pos: t, raw: raw, val: val, coerced: coerced}
+	return
+}
+
+// scope is the scope of an expression on the way th: it sees the values
+// captured on it.
+func (m *matcher) scope(th *thread) func() map[string]any {
+	return func() map[string]any { return m.a.scopeOf(th.last) }
+}
+
+// scopeOf is the scope of an expression on the way ending at last.
+func (a *alignment) scopeOf(last *trail) (env map[string]any) {
+	env = a.scope()
+	seen := map[string]bool{}
+	for tr := last; tr != nil; tr = tr.prev {
+		for _, c := range tr.caps {
+			if !c.spec.repeated && !seen[c.spec.name] {
+				env[c.spec.name], seen[c.spec.name] = c.val, true
+			}
+		}
+	}
+	return
+}
+
+// matchGrammar matches the positional tokens of fs against the grammar of
+// p, and reads the placeholders filled by its flags.
+func (p *Pattern) matchGrammar(fs *flagSet, env map[string]any) (a *alignment) {
+	a = &alignment{p: p, env: env, vars: make(map[string]*Var, p.nvars), w: p.scoreWeights()}
+	a.report.Weights = a.w
+	raws, set := fs.rest, fs.filled()
+	m := &matcher{a: a, prog: p.prog, raws: raws, glue: make([]string, len(raws)), set: set}
+	for x := 0; x+1 < len(raws) && len(fs.glue) > 0; x++ {
+		if fs.at[x+1] == fs.at[x]+1 {
+			m.glue[x] = fs.glue[fs.at[x]]
+		}
+	}
+	best, from, _ := m.run()
+	var steps []*trail
+	for tr := best.last; tr != nil; tr = tr.prev {
+		steps = append(steps, tr)
+	}
+	got := map[*varSpec][]capture{}
+	failed := map[*varSpec]bool{}
+	for x := len(steps) - 1; x >= 0; x-- {
+		st := steps[x]
+		for _, ss := range st.slots {
+			a.report.add(ss)
+		}
+		for _, c := range st.caps {
+			got[c.spec] = append(got[c.spec], c)
+		}
+		if te := st.te; te != nil {
+			a.fail(te)
+			if te.Suggest != "" {
+				a.suggestions = append(a.suggestions, Suggestion{Pos: te.Pos, Token: te.Token, Literal: te.Literal, Distance: editDistance(te.Token, te.Literal)})
+			}
+			st.n.walk(func(s *varSpec) { failed[s] = true })
+		}
+	}
+	extra := math.Max(a.w.Literal, a.w.Kind)
+	for t := from; t < len(raws); t++ {
+		a.fail(&TokenError{Reason: ExtraToken, Pos: t, Token: raws[t], Got: kindOf(a.eval(raws[t]))})
+		a.report.add(SlotScore{Pos: t, Token: raws[t], Max: extra})
+	}
+	fs.place(a)
+	for _, e := range p.elems {
+		if s := e.spec; s != nil && !set[s] {
+			a.vars[s.name] = a.gather(s, got[s], failed[s])
+		}
+	}
+	for _, te := range fs.errs {
+		a.fail(te)
+		if s := p.spec(te.Name); s != nil && te.Reason == MissingToken {
+			failed[s] = true
+		}
+	}
+	for _, ss := range fs.slots {
+		a.report.add(ss)
+	}
+	scope := func() map[string]any { return a.scopeOf(best.last) }
+	for _, fv := range fs.vals {
+		var caps []capture
+		for x, raw := range fv.raws {
+			c, ss, te := a.capture(fv.spec, raw, fv.at[x], scope)
+			a.report.add(ss)
+			if te != nil {
+				a.fail(te)
+				failed[fv.spec] = true
+				continue
+			}
+			caps = append(caps, c)
+		}
+		a.vars[fv.spec.name] = a.gather(fv.spec, caps, failed[fv.spec])
+	}
+	sort.SliceStable(a.errs, func(x, y int) bool { return a.errs[x].Pos < a.errs[y].Pos })
+	a.report.rescore()
+	return
+}
+
+// gather makes the Var of s from the values it captured. A placeholder
+// that repeats gets a slice typed after its Kind. One that took nothing
+// falls back to its default, unless its token was bad or missing.
+func (a *alignment) gather(s *varSpec, caps []capture, failed bool) (v *Var) {
+	if !s.repeated {
+		switch {
+		case len(caps) > 0:
+			v = s.newVar()
+			v.Value = caps[len(caps)-1].val
+		case failed:
+			v = s.newVar()
+		default:
+			v = s.defaultVar()
+			a.report.add(SlotScore{Pos: -1, Name: s.name, DefaultUsed: s.hasDef, Points: a.w.Default, Max: a.w.Default})
+		}
+		return
+	}
+	v = s.newVar()
+	typ := reflect.TypeOf(sliceValue(s.kind))
+	out := reflect.MakeSlice(typ, 0, len(caps))
+	raws := make([]string, len(caps))
+	for x, c := range caps {
+		out = reflect.Append(out, reflect.ValueOf(c.val).Convert(typ.Elem()))
+		raws[x] = c.raw
+	}
+	if s.variadic && s.constraint != nil && !failed {
+		if er := s.constraint.checkRange(float64(out.Len()), "count"); er != nil {
+			pos := -1
+			if len(caps) > 0 {
+				pos = caps[0].pos
+			}
+			a.fail(&TokenError{Reason: ConstraintViolation, Pos: pos, Name: s.name, Expected: s.kind, Token: strings.Join(raws, " "), Got: s.kind, Err: er})
+			a.report.revokeName(s.name)
+			out = out.Slice(0, 0)
+		}
+	}
+	v.Value = out.Interface()
+	return
+}
@@ -0,0 +1,127 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGrammarMatch(t *testing.T) {
+	tests := []struct {
+		format, line string
+		want         map[string]any
+		ok           bool
+	}{
+		{"(add|remove) ${item} [to ${list}]", "add milk", map[string]any{"item": "milk", "list": nil}, true},
+		{"(add|remove) ${item} [to ${list}]", "remove milk to groceries", map[string]any{"item": "milk", "list": "groceries"}, true},
+		{"(add|remove) ${item} [to ${list}]", "delete milk", nil, false},
+		{"(add|remove) ${item} [to ${list}]", "add milk to", nil, false},
+		{"set (${k}=${v:Int})+", "set a=1 b=2", map[string]any{"k": []any{"a", "b"}, "v": []int{1, 2}}, true},
+		{"set (${k}=${v:Int})+", "set", nil, false},
+		{"set (${k}=${v:Int})+", "set a=x", nil, false},
+		{"ls (-${flag:/[a-z]/})* ${dir}", "ls -l -a /tmp", map[string]any{"flag": []string{"l", "a"}, "dir": "/tmp"}, true},
+		{"ls (-${flag:/[a-z]/})* ${dir}", "ls /tmp", map[string]any{"flag": []string{}, "dir": "/tmp"}, true},
+		{"copy ${files...} to ${dest}", "copy a b to c", map[string]any{"files": []any{"a", "b"}, "dest": "c"}, true},
+		{"(start|stop) [(now|later)]", "stop later", map[string]any{}, true},
+		{"(start|stop) [(now|later)]", "stop soon", nil, false},
+		{"x (${a}|${b:Int}) y", "x 5 y", map[string]any{"a": 5, "b": nil}, true},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format).MatchString(tt.line)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s %q: got error %v", tt.format, tt.line, err)
+			continue
+		}
+		for name, want := range tt.want {
+			if got := in.Get(name).Value; !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %q: %s is %#v, want %#v", tt.format, tt.line, name, got, want)
+			}
+		}
+	}
+}
+
+func TestGrammarErrors(t *testing.T) {
+	tests := []struct {
+		format, line, want string
+	}{
+		{"(add|remove) ${item}", "delete milk", `token 0: expected "add", got "delete"`},
+		{"(add|remove) ${item} [to ${list}]", "add milk to", "token 3: missing list (Any)"},
+		{"set (${k}=${v})+", "set a=1 b", `token 2: unexpected "b"`},
+		{"deploy ${env} [${port:Int}]", "deploy prod x", `token 2: port expects Int, got String "x"`},
+	}
+	for _, tt := range tests {
+		_, err := MustCompile(tt.format).MatchString(tt.line)
+		if err == nil || !strings.HasSuffix(err.Error(), tt.want) {
+			t.Errorf("%s %q: got %v, want %s", tt.format, tt.line, err, tt.want)
+		}
+	}
+}
+
+func TestGrammarCompileErrors(t *testing.T) {
+	for _, format := range []string{
+		"((${a})+)+ end",
+		"(${a...})+",
+		"([${a}])+",
+		"(a|)",
+		"(a b",
+		"[a]?",
+		"(a)x",
+	} {
+		if _, err := Compile(format); err == nil {
+			t.Errorf("%q compiled", format)
+		}
+	}
+}
+
+func TestGrammarIsNotExponential(t *testing.T) {
+	p := MustCompile("((${a}|${b}) (${c}|x)?)+ end")
+	line := strings.Repeat("x ", 2000)
+	start := time.Now()
+	if _, err := p.MatchString(line); err == nil {
+		t.Error("a line without end matched")
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("matching took %v", d)
+	}
+}
+
+func TestGrammarArgs(t *testing.T) {
+	args := MustCompile("(add ${item}|drop ${n:Int}) [to ${list}] (${tag})* ${rest...}").Args()
+	want := map[string][2]bool{
+		"item": {true, false},
+		"n":    {true, false},
+		"list": {true, false},
+		"tag":  {true, true},
+		"rest": {false, true},
+	}
+	for _, a := range args {
+		if w := want[a.Name]; a.Optional != w[0] || a.Variadic != w[1] {
+			t.Errorf("%s: Optional %v Variadic %v, want %v", a.Name, a.Optional, a.Variadic, w)
+		}
+	}
+}
+
+func TestGrammarComplete(t *testing.T) {
+	r := NewRouter()
+	r.Handle("(add|remove) ${item} [to ${list}]", nil)
+	tests := []struct {
+		partial string
+		want    []string
+	}{
+		{"", []string{"add", "remove"}},
+		{"re", []string{"remove"}},
+		{"add milk ", []string{"to"}},
+		{"add milk to ", []string{"<list:Any>"}},
+		{"delete ", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range r.Complete(tt.partial, len(tt.partial)) {
+			got = append(got, c.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.partial, got, tt.want)
+		}
+	}
+}
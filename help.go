@@ -0,0 +1,347 @@
+package input
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// HelpFormat selects the markup written by WriteHelp.
+type HelpFormat int
+
+const (
+	TextHelp HelpFormat = iota
+	MarkdownHelp
+	ManHelp
+)
+
+// helpDoc holds the descriptions given to a Pattern by WithSummary,
+// WithGroup and WithArgDoc.
+type helpDoc struct {
+	summary string
+	group   string
+	args    map[string]string
+}
+
+func (p *Pattern) help() *helpDoc {
+	if p.doc == nil {
+		p.doc = &helpDoc{}
+	}
+	return p.doc
+}
+
+// ArgHelp describes a placeholder of a format for help output. Default and
+// Constraint are empty when the placeholder has none.
+type ArgHelp struct {
+	Name        string
+	Kind        string
+	Default     string
+	Constraint  string
+	Description string
+	Optional    bool
+	Variadic    bool
+}
+
+// Summary is the description set by WithSummary.
+func (p *Pattern) Summary() (s string) {
+	if p.doc != nil {
+		s = p.doc.summary
+	}
+	return
+}
+
+// Group is the group set by WithGroup.
+func (p *Pattern) Group() (g string) {
+	if p.doc != nil {
+		g = p.doc.group
+	}
+	return
+}
+
+// Synopsis is the format with each placeholder written as `<name>`,
+// `<name>...` when variadic, and in brackets when optional:
+//
+//	deploy <env> [<port>] to <hosts>...
+func (p *Pattern) Synopsis() string {
+	var b strings.Builder
+	format := p.format
+	for _, e := range p.elems {
+		if e.spec == nil {
+			continue
+		}
+		start := strings.Index(format, "${")
+		end := start + placeholderEnd(format[start:])
+		b.WriteString(format[:start])
+		format = format[end:]
+		word := "<" + e.spec.name + ">"
+		if e.spec.variadic {
+			word += "..."
+		}
+		if e.spec.optional {
+			word = "[" + word + "]"
+		}
+		b.WriteString(word)
+	}
+	b.WriteString(format)
+	return b.String()
+}
+
+// Args describes the placeholders of p in order. A placeholder in an
+// optional group, in one of several alternatives or in a group that may
+// be left out is optional, and one in a repeated group is variadic.
+func (p *Pattern) Args() (args []ArgHelp) {
+	optional := map[*varSpec]bool{}
+	p.root.leftOut(false, optional)
+	for _, e := range p.elems {
+		s := e.spec
+		if s == nil {
+			continue
+		}
+		a := ArgHelp{Name: s.name, Kind: KindString(s.kind), Optional: s.optional || optional[s], Variadic: s.repeated}
+		if s.hasDef {
+			if str, err := renderKind(s.kind, s.def, p.separators()); err == nil {
+				a.Default = str
+			} else {
+				a.Default = fmt.Sprint(s.def)
+			}
+		}
+		if s.constraint != nil {
+			a.Constraint = s.constraint.text
+		}
+		if p.doc != nil {
+			a.Description = p.doc.args[s.name]
+		}
+		args = append(args, a)
+	}
+	return
+}
+
+// leftOut adds to set the placeholders of n that a line may leave out. out
+// is set when n itself may be left out.
+func (n *node) leftOut(out bool, set map[*varSpec]bool) {
+	switch n.kind {
+	case wordNode:
+		if out {
+			n.walk(func(s *varSpec) { set[s] = true })
+		}
+	case altNode:
+		out = out || len(n.subs) > 1
+	case optNode:
+		out = true
+	case repNode:
+		out = out || n.min == 0
+	}
+	for _, sub := range n.subs {
+		sub.leftOut(out, set)
+	}
+}
+
+// WriteHelp writes the synopsis, summary and argument table of p.
+func (p *Pattern) WriteHelp(w io.Writer, f HelpFormat) (err error) {
+	err = writeHelp(w, "", f, []*Pattern{p})
+	return
+}
+
+// WriteHelp writes help for every format of r, listed by group in the
+// order the groups were first registered. name is the program name used as
+// the title, and as the page name of a man page.
+func (r *Router) WriteHelp(w io.Writer, name string, f HelpFormat) (err error) {
+	routes := r.Routes()
+	ps := make([]*Pattern, len(routes))
+	for x, rt := range routes {
+		ps[x] = rt.pattern
+	}
+	err = writeHelp(w, name, f, ps)
+	return
+}
+
+func writeHelp(w io.Writer, name string, f HelpFormat, ps []*Pattern) (err error) {
+	var groups []string
+	byGroup := map[string][]*Pattern{}
+	for _, p := range ps {
+		g := p.Group()
+		if _, ok := byGroup[g]; !ok {
+			groups = append(groups, g)
+		}
+		byGroup[g] = append(byGroup[g], p)
+	}
+	if len(groups) == 0 {
+		groups = []string{""}
+	}
+	var b strings.Builder
+	switch f {
+	case TextHelp:
+		writeText(&b, name, groups, byGroup)
+	case MarkdownHelp:
+		writeMarkdown(&b, name, groups, byGroup)
+	case ManHelp:
+		writeMan(&b, name, groups, byGroup)
+	default:
+		err = fmt.Errorf("input: unknown help format %d", f)
+		return
+	}
+	_, err = io.WriteString(w, b.String())
+	return
+}
+
+// groupTitle is the heading of group g when there is more than one group,
+// or a single named one.
+func groupTitle(g string, groups []string) (title string, ok bool) {
+	if g == "" && len(groups) == 1 {
+		return
+	}
+	if title, ok = g, true; title == "" {
+		title = "Commands"
+	}
+	return
+}
+
+// argNotes joins the kind, default and constraint of a into one line.
+func argNotes(a ArgHelp) string {
+	notes := []string{a.Kind}
+	if a.Variadic {
+		notes[0] += "..."
+	}
+	if a.Constraint != "" {
+		notes = append(notes, a.Constraint)
+	}
+	if a.Default != "" {
+		notes = append(notes, "default "+a.Default)
+	} else if a.Optional {
+		notes = append(notes, "optional")
+	}
+	return strings.Join(notes, ", ")
+}
+
+func writeText(b *strings.Builder, name string, groups []string, byGroup map[string][]*Pattern) {
+	if name != "" {
+		fmt.Fprintf(b, "%s\n\n", name)
+	}
+	for _, g := range groups {
+		indent := ""
+		if title, ok := groupTitle(g, groups); ok {
+			fmt.Fprintf(b, "%s:\n", title)
+			indent = "  "
+		}
+		for _, p := range byGroup[g] {
+			fmt.Fprintf(b, "%s%s\n", indent, p.Synopsis())
+			if s := p.Summary(); s != "" {
+				fmt.Fprintf(b, "%s    %s\n", indent, s)
+			}
+			if args := p.Args(); len(args) > 0 {
+				var table strings.Builder
+				tw := tabwriter.NewWriter(&table, 0, 4, 2, ' ', 0)
+				for _, a := range args {
+					fmt.Fprintf(tw, "%s    %s\t%s\t%s\n", indent, a.Name, argNotes(a), a.Description)
+				}
+				tw.Flush()
+				for _, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+					b.WriteString(strings.TrimRight(line, " ") + "\n")
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+}
+
+func writeMarkdown(b *strings.Builder, name string, groups []string, byGroup map[string][]*Pattern) {
+	if name != "" {
+		fmt.Fprintf(b, "# %s\n\n", name)
+	}
+	level := "##"
+	if _, ok := groupTitle(groups[0], groups); ok {
+		level = "###"
+	}
+	for _, g := range groups {
+		if title, ok := groupTitle(g, groups); ok {
+			fmt.Fprintf(b, "## %s\n\n", title)
+		}
+		for _, p := range byGroup[g] {
+			fmt.Fprintf(b, "%s `%s`\n\n", level, p.Synopsis())
+			if s := p.Summary(); s != "" {
+				fmt.Fprintf(b, "%s\n\n", s)
+			}
+			args := p.Args()
+			if len(args) == 0 {
+				continue
+			}
+			b.WriteString("| Argument | Kind | Default | Constraint | Description |\n")
+			b.WriteString("| --- | --- | --- | --- | --- |\n")
+			for _, a := range args {
+				kind := a.Kind
+				if a.Variadic {
+					kind += "..."
+				}
+				def := mdCode(a.Default)
+				if def == "" && a.Optional {
+					def = "optional"
+				}
+				fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s |\n", a.Name, kind, def, mdCode(a.Constraint), mdCell(a.Description))
+			}
+			b.WriteString("\n")
+		}
+	}
+}
+
+// mdCode writes s as inline code in a table cell.
+func mdCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + mdCell(s) + "`"
+}
+
+func mdCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func writeMan(b *strings.Builder, name string, groups []string, byGroup map[string][]*Pattern) {
+	title := name
+	if ps := byGroup[groups[0]]; title == "" && len(ps) > 0 {
+		title = strings.Fields(ps[0].Synopsis())[0]
+	}
+	fmt.Fprintf(b, ".TH %q 1\n", strings.ToUpper(title))
+	fmt.Fprintf(b, ".SH NAME\n%s\n", roff(title))
+	b.WriteString(".SH SYNOPSIS\n")
+	for _, g := range groups {
+		for _, p := range byGroup[g] {
+			fmt.Fprintf(b, ".B %s\n.br\n", roff(p.Synopsis()))
+		}
+	}
+	for _, g := range groups {
+		head := "COMMANDS"
+		if t, ok := groupTitle(g, groups); ok {
+			head = strings.ToUpper(t)
+		}
+		fmt.Fprintf(b, ".SH %s\n", roff(head))
+		for _, p := range byGroup[g] {
+			fmt.Fprintf(b, ".TP\n.B %s\n", roff(p.Synopsis()))
+			if s := p.Summary(); s != "" {
+				fmt.Fprintf(b, "%s\n", roff(s))
+			}
+			args := p.Args()
+			if len(args) == 0 {
+				continue
+			}
+			b.WriteString(".RS\n")
+			for _, a := range args {
+				fmt.Fprintf(b, ".TP\n.I %s\n%s", roff(a.Name), roff(argNotes(a)))
+				if a.Description != "" {
+					fmt.Fprintf(b, "\n.br\n%s", roff(a.Description))
+				}
+				b.WriteString("\n")
+			}
+			b.WriteString(".RE\n")
+		}
+	}
+}
+
+// roff escapes s as a line of text, so that it is not read as a request.
+func roff(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`, "\n", " ").Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
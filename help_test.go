@@ -0,0 +1,68 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func helpRouter() (r *Router) {
+	r = NewRouter()
+	r.Handle("deploy ${env:String(dev|prod)} ${port:Int=80}", nil, WithSummary("Deploy the app."), WithGroup("ops"), WithArgDoc("env", "where to deploy"))
+	r.Handle("tag ${id:Int} ${labels?...:String}", nil, WithGroup("misc"))
+	return
+}
+
+func TestWriteHelp(t *testing.T) {
+	tests := []struct {
+		f    HelpFormat
+		want []string
+	}{
+		{TextHelp, []string{
+			"app\n\nops:\n  deploy <env> [<port>]\n      Deploy the app.\n",
+			"      env   String, dev|prod  where to deploy\n      port  Int, default 80\n",
+			"misc:\n  tag <id> [<labels>...]\n      id      Int\n      labels  String..., optional\n",
+		}},
+		{MarkdownHelp, []string{
+			"# app\n\n## ops\n\n### `deploy <env> [<port>]`\n\nDeploy the app.\n",
+			"| Argument | Kind | Default | Constraint | Description |\n| --- | --- | --- | --- | --- |\n",
+			"| `env` | String |  | `dev\\|prod` | where to deploy |\n| `port` | Int | `80` |  |  |\n",
+			"## misc\n\n### `tag <id> [<labels>...]`\n",
+			"| `labels` | String... | optional |  |  |\n",
+		}},
+		{ManHelp, []string{
+			".TH \"APP\" 1\n.SH NAME\napp\n.SH SYNOPSIS\n.B deploy <env> [<port>]\n.br\n.B tag <id> [<labels>...]\n",
+			".SH OPS\n.TP\n.B deploy <env> [<port>]\nDeploy the app.\n.RS\n.TP\n.I env\nString, dev|prod\n.br\nwhere to deploy\n",
+			".SH MISC\n",
+		}},
+	}
+	r := helpRouter()
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := r.WriteHelp(&b, "app", tt.f); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("help %d does not contain %q:\n%s", tt.f, want, b.String())
+			}
+		}
+	}
+}
+
+func TestPatternHelp(t *testing.T) {
+	p := MustCompile("deploy ${env:String(dev|prod)} ${port:Int=80}", WithSummary("Deploy the app."), WithGroup("ops"))
+	if p.Summary() != "Deploy the app." || p.Group() != "ops" {
+		t.Errorf("got summary %q and group %q", p.Summary(), p.Group())
+	}
+	var got []string
+	for _, a := range p.Args() {
+		got = append(got, a.Name)
+	}
+	if !reflect.DeepEqual(got, []string{"env", "port"}) {
+		t.Errorf("Args: got %v", got)
+	}
+	if _, err := Compile("x ${a}", WithArgDoc("b", "text")); err == nil || err.Error() != `input: description for unknown placeholder "b"` {
+		t.Errorf("WithArgDoc of an unknown placeholder: got %v", err)
+	}
+}
@@ -1,18 +1,16 @@
 package input
 
 import (
-	"fmt"
 	"io"
 	"io/ioutil"
 	"strings"
 
-	"github.com/antonmedv/expr"
 	"github.com/hyprstereo/go-dao/utils/template/ft"
 )
 
-func Read(format string, in string) (i *Input, score float64, err error) {
+func Read(format string, in string, opts ...Option) (i *Input, score float64, err error) {
 	i = &Input{}
-	score, err = i.Read(format, strings.NewReader(in))
+	score, err = i.Read(format, strings.NewReader(in), opts...)
 	return
 }
 
@@ -25,50 +23,25 @@ type Input struct {
 	line     string
 	fmtValue string
 	vars     map[string]*Var
-}
+	pattern  *Pattern
+	score    float64
+	report   *ScoreReport
+	err      error
 
-func (i *Input) Read(format string, r io.Reader) (score float64, err error) {
-	i.vars = make(map[string]*Var)
-	words := Split(format)
-	matchers := []string{}
-	cnt := 0
-	//vars := make([]any, 0)
-	out, _ := ioutil.ReadAll(r)
-	i.line = string(out)
-	outs := SplitArgs(string(out))
-	var scores = 0
-	for x := 0; x < len(words); x++ {
-		if isVar(words[x]) {
-			v := extractVar(words[x], cnt)
-			if v.expectedKind == Array {
-				fmt.Println("kindof", KindString(kindOf(outs[x])))
-				if kindOf(outs[x]) == String {
-					v.Value = outs[x]
-				} else {
-					v.Value = outs[x].([]any)[0]
-				}
-				scores++
-			} else {
-				if v.expectedKind == kindOf(outs[x]) {
-					score++
-				}
-				v.Value = outs[x]
-				//v.Value = "expected " + KindString(v.expectedKind) + ", got " + KindString(kindOf(out[x]))
-			}
-			i.vars[v.Name] = v
-			matchers = append(matchers, v.fmtValue)
+	suggestions []Suggestion
+}
 
-			cnt++
-		} else {
-			if words[x] == outs[x] {
-				scores++
-			}
-			matchers = append(matchers, words[x])
-		}
+func (i *Input) Read(format string, r io.Reader, opts ...Option) (score float64, err error) {
+	p, err := Compile(format, opts...)
+	if err != nil {
+		return
 	}
-
-	i.fmtValue = strings.Join(matchers, " ")
-	score = float64(scores) / float64(len(words))
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	err = p.read(i, string(out), nil)
+	score = i.score
 	return
 }
 
@@ -105,15 +78,49 @@ func (i *Input) Read(format string, r io.Reader) (score float64, err error) {
 // 	return
 // }
 
+// Matches returns the vars that captured a value, and the score of the
+// match.
 func (i *Input) Matches() (vars []*Var, score float64) {
-	scored := 0
 	for _, v := range i.vars {
 		if v.Value != nil {
 			vars = append(vars, v)
-			scored++
 		}
 	}
-	score = float64(scored) / float64(len(i.vars))
+	score = i.score
+	return
+}
+
+// Score is the score of the last Read or Match that produced i, as
+// described by Weights.
+func (i *Input) Score() (score float64) {
+	score = i.score
+	return
+}
+
+// Report breaks Score down by slot. It is nil for an Input that was not
+// matched.
+func (i *Input) Report() (r *ScoreReport) {
+	r = i.report
+	return
+}
+
+// Err is the *MatchError of the last Read or Match that produced i, or nil
+// when every token matched.
+func (i *Input) Err() (err error) {
+	err = i.err
+	return
+}
+
+// Suggestions lists the literal words that mistyped tokens probably meant,
+// when the Pattern was compiled WithFuzzy.
+func (i *Input) Suggestions() (s []Suggestion) {
+	s = i.suggestions
+	return
+}
+
+// Pattern is the compiled format i was matched against.
+func (i *Input) Pattern() (p *Pattern) {
+	p = i.pattern
 	return
 }
 
@@ -160,71 +167,33 @@ func isVar(v string) (ok bool) {
 	return
 }
 
-func extractVar(v string, pos int) (nv *Var) {
-	v = strings.TrimPrefix(v, "${")
-	v = strings.TrimSuffix(v, "}")
-	if strings.Contains(v, ":") {
-		toks := strings.Split(v, ":")
-		knd := StringToKind(toks[1])
-		nv = &Var{
-			Name:         toks[0],
-			Pos:          pos,
-			expectedKind: knd,
-			fmtValue:     KindFmtSymbol(knd),
-		}
-	} else {
-		nv = &Var{
-			Name:         v,
-			Pos:          pos,
-			expectedKind: Any,
-			fmtValue:     KindFmtSymbol(Any),
-		}
-	}
+// Split splits value into raw tokens as Tokenize does, keeping their
+// quotes. A quote or bracket left open runs to the end of value.
+func Split(value string) (res []string) {
+	res = DefaultSeparators.split(value)
 	return
 }
 
-func Split(value string) (res []string) {
-	canSplit := true
-	inString := false
-	lvl := 0
-	tags := strings.FieldsFunc(value, func(r rune) bool {
-		if r == '{' || r == '[' {
-			lvl++
-			canSplit = false
-		} else if r == '}' || r == ']' {
-			if lvl > 0 {
-				lvl--
-			}
-			canSplit = lvl == 0
-		}
-		if r == '\'' || r == '"' {
-			inString = !inString
-
-		}
+// span is the byte offsets of a token within the line it was split from.
+type span struct {
+	start, end int
+}
 
-		if canSplit {
-			return (r == ' ' || r == ',' || r == ':') && !inString
-		} else {
-			return false
-		}
-	})
+func SplitArgs(value string) (res []any) {
+	tags := Split(value)
+	res = make([]any, 0, len(tags))
 	for _, t := range tags {
-		if strings.TrimSpace(t) != "" {
-			res = append(res, t)
-		}
+		res = append(res, evalArg(t))
 	}
 	return
 }
 
-func SplitArgs(value string) (res []any) {
-	tags := Split(value)
-	res = make([]any, 0)
-	for _, t := range tags {
-		if out, er := expr.Eval(t, nil); er == nil {
-			res = append(res, out)
-		} else {
-			res = append(res, t)
-		}
+// evalArg reads a token as a literal, falling back to its unquoted text.
+func evalArg(t string) (v any) {
+	if out, er := ParseLiteral(t); er == nil {
+		v = out
+	} else {
+		v = unquote(t)
 	}
 	return
 }
@@ -3,7 +3,7 @@ package input
 import (
 	"fmt"
 	"reflect"
-	"strconv"
+	"time"
 )
 
 type Kind = uint8
@@ -30,16 +30,26 @@ const (
 	Array
 	Any
 	RGBHex
+	Bytes
+	Duration
 )
 
 func KindString(typ Kind) (str string) {
 	switch typ {
 	case RGBHex:
 		str = "RGBHex"
+	case Bytes:
+		str = "Bytes"
+	case Duration:
+		str = "Duration"
 	case Null:
 		str = "Null"
 	case Int:
 		str = "Int"
+	case Uint:
+		str = "Uint"
+	case String:
+		str = "String"
 	case Byte:
 		str = "Uint32"
 	case Bool:
@@ -53,13 +63,32 @@ func KindString(typ Kind) (str string) {
 	case Any:
 		str = "Any"
 	default:
-		str = fmt.Sprint(typ)
+		if e, ok := kindDef(typ); ok {
+			str = e.name
+		} else {
+			str = fmt.Sprint(typ)
+		}
 	}
 	return
 }
 
 func kindOf(v any) (s Kind) {
 	tv := reflect.TypeOf(v)
+	if tv == nil {
+		s = Null
+		return
+	}
+	switch tv {
+	case colorType:
+		s = RGBHex
+		return
+	case byteSizeType:
+		s = Bytes
+		return
+	case durationType:
+		s = Duration
+		return
+	}
 	switch tv.Kind() {
 	case reflect.Array:
 		s = Array
@@ -78,7 +107,11 @@ func kindOf(v any) (s Kind) {
 	case reflect.Interface:
 		s = Any
 	case reflect.Slice:
-		s = Byte
+		if tv.Elem().Kind() == reflect.Uint8 {
+			s = Byte
+		} else {
+			s = Array
+		}
 	case reflect.String:
 		s = String
 	default:
@@ -87,33 +120,53 @@ func kindOf(v any) (s Kind) {
 	return
 }
 
+var kindNames = map[string]Kind{
+	"String":   String,
+	"RGBHex":   RGBHex,
+	"Color":    RGBHex,
+	"Bytes":    Bytes,
+	"Duration": Duration,
+	"Null":     Null,
+	"Int":      Int,
+	"Uint":     Uint,
+	"Byte":     Byte,
+	"Bool":     Bool,
+	"Float":    Float,
+	"Float32":  Float,
+	"Map":      Map,
+	"Array":    Array,
+	"Any":      Any,
+}
+
+// acceptsKind reports whether val can be stored in a placeholder of the
+// expected Kind.
+func acceptsKind(expected Kind, val any) (ok bool) {
+	got := kindOf(val)
+	switch expected {
+	case Any:
+		ok = true
+	case Array:
+		ok = got == Array
+	case Int:
+		ok = got == Int || got == Uint
+	case Uint:
+		ok = got == Uint || (got == Int && reflect.ValueOf(val).Int() >= 0)
+	default:
+		ok = got == expected
+	}
+	return
+}
+
 func StringToKind(typ string) (str Kind) {
-	switch typ {
-	case "String":
-		str = String
-	case "RGBHex":
-		str = RGBHex
-	case "Null":
-		str = Null
-	case "Int":
-		str = Int
-	case "Uint":
-		str = Uint
-	case "Byte":
-		str = Byte
-	case "Bool":
-		str = Bool
-	case "Float":
-		str = Float
-	case "Float32":
-		str = Float
-
-	case "Map":
-		str = Map
-	case "Array":
-		str = Array
-	case "Any":
-		str = Any
+	str, _ = lookupKind(typ)
+	return
+}
+
+// lookupKind is StringToKind that also reports whether typ names a known
+// Kind, so callers can tell an unknown name apart from "Null".
+func lookupKind(typ string) (k Kind, ok bool) {
+	if k, ok = kindNames[typ]; !ok {
+		k, ok = customKind(typ)
 	}
 	return
 }
@@ -128,10 +181,14 @@ func KindFmtSymbol(k Kind) (s string) {
 		s = "%s"
 	case Bool:
 		s = "%t"
-	case Any, Map, Array, Byte:
+	case Any, Map, Array, Byte, Bytes, Duration:
 		s = "%v"
 	case RGBHex:
 		s = "%02x%02x%02x"
+	default:
+		if _, ok := kindDef(k); ok {
+			s = "%v"
+		}
 	}
 	return
 }
@@ -152,14 +209,51 @@ func KindValue(k Kind) (sy any) {
 	case Any, Map:
 		s = map[string]any{}
 	case RGBHex:
-		s = "%02x%02x%02x"
+		s = Color{}
+	case Bytes:
+		s = ByteSize(0)
+	case Duration:
+		s = time.Duration(0)
 	case Byte:
 		s = []byte("")
+	default:
+		if e, ok := kindDef(k); ok && e.def.Zero != nil {
+			s = e.def.Zero()
+		}
+	}
+	if s == nil {
+		s = map[string]any{}
 	}
 	sy = reflect.New(reflect.ValueOf(s).Type()).Interface()
 	return
 }
 
+// sliceValue is the empty slice a variadic placeholder of Kind k collects
+// its tokens into.
+func sliceValue(k Kind) (s any) {
+	switch k {
+	case Int:
+		s = []int{}
+	case Uint:
+		s = []uint{}
+	case Float:
+		s = []float64{}
+	case String:
+		s = []string{}
+	case RGBHex:
+		s = []Color{}
+	case Bytes:
+		s = []ByteSize{}
+	case Duration:
+		s = []time.Duration{}
+	case Bool:
+		s = []bool{}
+	default:
+		s = []any{}
+	}
+	return
+}
+
 func pointerValue(v any, k Kind) (val any) {
 	va := reflect.ValueOf(v)
 	if va.Kind() == reflect.Ptr {
@@ -172,9 +266,12 @@ func pointerValue(v any, k Kind) (val any) {
 }
 
 type Var struct {
-	Pos          int
-	Value        any
-	Name         string
+	Pos   int
+	Value any
+	Name  string
+	// Defaulted is set when the placeholder was left out of the input and
+	// Value holds its default instead of a supplied token.
+	Defaulted    bool
 	fmtValue     string
 	expectedKind Kind
 }
@@ -183,41 +280,13 @@ func (v *Var) String() string {
 	return fmt.Sprintf("%s (%s): %v", v.Name, KindString(v.expectedKind), v.Value)
 }
 
-func (v *Var) tryConvert(value any) (err error) {
-	switch v.expectedKind {
-	case String:
-		v.Value = value
-	case Int:
-		if va, er := strconv.ParseInt(value.(string), 0, 0); er != nil {
-			v.Value = "not_" + KindString(v.expectedKind)
-		} else {
-			v.Value = va
-		}
-	case Float:
-		if va, er := strconv.ParseFloat(value.(string), 64); er != nil {
-			v.Value = "not_" + KindString(v.expectedKind)
-		} else {
-			v.Value = va
-		}
-	case Uint:
-		if va, er := strconv.ParseUint(value.(string), 0, 0); er != nil {
-			v.Value = "not_" + KindString(v.expectedKind)
-		} else {
-			v.Value = va
-		}
-	case Bool:
-		if va, er := strconv.ParseBool(value.(string)); er != nil {
-			v.Value = "not_" + KindString(v.expectedKind)
-		} else {
-			v.Value = va
-		}
-	default:
-		v.Value = value
-	}
-	return
-}
-
+// Type is the reflect.Kind of Value, or reflect.Invalid when the var
+// captured nothing.
 func (v *Var) Type() (k reflect.Kind) {
+	if v.Value == nil {
+		k = reflect.Invalid
+		return
+	}
 	k = reflect.TypeOf(v.Value).Kind()
 	return
 }
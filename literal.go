@@ -0,0 +1,232 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseLiteral parses s as a literal value without evaluating anything:
+// integers in any base Go accepts (42, -0x2a, 0o52, 0b101010, 1_000),
+// floats, single or double quoted strings with Go escapes, true, false,
+// nil, arrays `[1, "a"]` and maps `{key: 1, "other key": [2]}`. Values have
+// the types expr would give them: int, float64, string, bool, nil, []any
+// and map[string]any. Anything else, including the whole of s not being
+// one literal, is an error.
+func ParseLiteral(s string) (v any, err error) {
+	lp := &literalParser{src: s}
+	lp.skipSpace()
+	if v, err = lp.value(); err != nil {
+		return
+	}
+	if lp.skipSpace(); lp.pos < len(s) {
+		v, err = nil, lp.errorf("unexpected %q", s[lp.pos:])
+	}
+	return
+}
+
+type literalParser struct {
+	src string
+	pos int
+}
+
+func (lp *literalParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("input: literal at offset %d: %s", lp.pos, fmt.Sprintf(format, args...))
+}
+
+func (lp *literalParser) skipSpace() {
+	for lp.pos < len(lp.src) {
+		r, n := utf8.DecodeRuneInString(lp.src[lp.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		lp.pos += n
+	}
+}
+
+func (lp *literalParser) peek() (c byte) {
+	if lp.pos < len(lp.src) {
+		c = lp.src[lp.pos]
+	}
+	return
+}
+
+func (lp *literalParser) value() (v any, err error) {
+	switch c := lp.peek(); {
+	case c == 0:
+		err = lp.errorf("missing value")
+	case c == '"' || c == '\'':
+		v, err = lp.quoted()
+	case c == '[':
+		v, err = lp.array()
+	case c == '{':
+		v, err = lp.object()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		v, err = lp.number()
+	default:
+		switch word := lp.word(); word {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "nil":
+		default:
+			err = lp.errorf("%q is not a literal", word)
+		}
+	}
+	return
+}
+
+// word reads an identifier.
+func (lp *literalParser) word() (w string) {
+	start := lp.pos
+	for lp.pos < len(lp.src) {
+		r, n := utf8.DecodeRuneInString(lp.src[lp.pos:])
+		if r != '_' && !unicode.IsLetter(r) && (lp.pos == start || !unicode.IsDigit(r)) {
+			break
+		}
+		lp.pos += n
+	}
+	w = lp.src[start:lp.pos]
+	return
+}
+
+func (lp *literalParser) number() (v any, err error) {
+	start := lp.pos
+	if c := lp.peek(); c == '-' || c == '+' {
+		lp.pos++
+	}
+	for lp.pos < len(lp.src) {
+		c := lp.src[lp.pos]
+		isExp := (c == '-' || c == '+') && (lp.src[lp.pos-1] == 'e' || lp.src[lp.pos-1] == 'E') && !strings.HasPrefix(strings.TrimLeft(lp.src[start:], "+-"), "0x")
+		if !isExp && c != '.' && c != '_' && !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') {
+			break
+		}
+		lp.pos++
+	}
+	text := lp.src[start:lp.pos]
+	digits := strings.TrimLeft(text, "+-")
+	base := 0
+	if len(digits) > 1 && digits[0] == '0' && strings.Trim(digits, "0123456789_") == "" {
+		// A leading zero is not octal here: people type 007 and 08.
+		base = 10
+	}
+	if n, er := strconv.ParseInt(text, base, 0); er == nil {
+		v = int(n)
+		return
+	} else if errors.Is(er, strconv.ErrRange) {
+		err = lp.errorf("%s overflows int", text)
+		return
+	}
+	if f, er := strconv.ParseFloat(text, 64); er == nil && digits != "" && (digits[0] == '.' || (digits[0] >= '0' && digits[0] <= '9')) {
+		v = f
+		return
+	}
+	err = lp.errorf("%q is not a number", text)
+	return
+}
+
+func (lp *literalParser) quoted() (v any, err error) {
+	quote := lp.src[lp.pos]
+	lp.pos++
+	var b strings.Builder
+	for {
+		if lp.pos >= len(lp.src) {
+			err = lp.errorf("unterminated string")
+			return
+		}
+		if lp.src[lp.pos] == quote {
+			lp.pos++
+			break
+		}
+		r, multibyte, tail, er := strconv.UnquoteChar(lp.src[lp.pos:], quote)
+		if er != nil {
+			err = lp.errorf("bad escape in string")
+			return
+		}
+		if multibyte || r >= utf8.RuneSelf {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(byte(r))
+		}
+		lp.pos = len(lp.src) - len(tail)
+	}
+	v = b.String()
+	return
+}
+
+func (lp *literalParser) array() (v any, err error) {
+	lp.pos++
+	arr := []any{}
+	for {
+		lp.skipSpace()
+		if lp.peek() == ']' {
+			lp.pos++
+			v = arr
+			return
+		}
+		var item any
+		if item, err = lp.value(); err != nil {
+			return
+		}
+		arr = append(arr, item)
+		if err = lp.separator(']'); err != nil {
+			return
+		}
+	}
+}
+
+func (lp *literalParser) object() (v any, err error) {
+	lp.pos++
+	obj := map[string]any{}
+	for {
+		lp.skipSpace()
+		if lp.peek() == '}' {
+			lp.pos++
+			v = obj
+			return
+		}
+		var key string
+		if c := lp.peek(); c == '"' || c == '\'' {
+			var k any
+			if k, err = lp.quoted(); err != nil {
+				return
+			}
+			key = k.(string)
+		} else if key = lp.word(); key == "" {
+			err = lp.errorf("missing map key")
+			return
+		}
+		if lp.skipSpace(); lp.peek() != ':' {
+			err = lp.errorf("missing `:` after map key %q", key)
+			return
+		}
+		lp.pos++
+		lp.skipSpace()
+		if obj[key], err = lp.value(); err != nil {
+			return
+		}
+		if err = lp.separator('}'); err != nil {
+			return
+		}
+	}
+}
+
+// separator reads the comma between items, leaving a closing bracket for
+// the caller.
+func (lp *literalParser) separator(closing byte) (err error) {
+	lp.skipSpace()
+	switch lp.peek() {
+	case ',':
+		lp.pos++
+	case closing:
+	case 0:
+		err = lp.errorf("missing `%c`", closing)
+	default:
+		err = lp.errorf("unexpected %q", lp.src[lp.pos:])
+	}
+	return
+}
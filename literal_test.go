@@ -0,0 +1,73 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLiteral(t *testing.T) {
+	tests := []struct {
+		in   string
+		want any
+	}{
+		{"42", 42},
+		{"-0x2a", -42},
+		{"0o52", 42},
+		{"0b101010", 42},
+		{"1_000", 1000},
+		{"007", 7},
+		{"+5", 5},
+		{"1.5", 1.5},
+		{".5", 0.5},
+		{"-2e3", -2000.0},
+		{`"a\tb"`, "a\tb"},
+		{`'x'`, "x"},
+		{"true", true},
+		{"false", false},
+		{"nil", nil},
+		{"  7  ", 7},
+		{`[1, "a"]`, []any{1, "a"}},
+		{"[1,]", []any{1}},
+		{"[]", []any{}},
+		{`{key: 1, "other key": [2]}`, map[string]any{"key": 1, "other key": []any{2}}},
+		{"{}", map[string]any{}},
+	}
+	for _, tt := range tests {
+		if got, err := ParseLiteral(tt.in); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLiteral(%q) = %#v, %v, want %#v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseLiteralErrors(t *testing.T) {
+	tests := []struct {
+		in, err string
+	}{
+		{"1+1", `input: literal at offset 1: unexpected "+1"`},
+		{"foo(1)", `input: literal at offset 3: "foo" is not a literal`},
+		{"abc", `input: literal at offset 3: "abc" is not a literal`},
+		{"1 2", `input: literal at offset 2: unexpected "2"`},
+		{"[1,", "input: literal at offset 3: missing value"},
+		{"[1,,2]", `input: literal at offset 3: "" is not a literal`},
+		{"{a 1}", "input: literal at offset 3: missing `:` after map key \"a\""},
+		{`"abc`, "input: literal at offset 4: unterminated string"},
+		{"0x", `input: literal at offset 2: "0x" is not a number`},
+		{"1e", `input: literal at offset 2: "1e" is not a number`},
+	}
+	for _, tt := range tests {
+		if v, err := ParseLiteral(tt.in); err == nil || err.Error() != tt.err {
+			t.Errorf("ParseLiteral(%q) = %#v, %v, want error %s", tt.in, v, err, tt.err)
+		}
+	}
+}
+
+func TestNoExprByDefault(t *testing.T) {
+	got := SplitArgs(`1+1 "a b" 0x10 [1,2] foo(1)`)
+	if want := []any{"1+1", "a b", 16, []any{1, 2}, "foo(1)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitArgs: got %#v, want %#v", got, want)
+	}
+	in, _ := MustCompile("eval ${x}").MatchString("eval 1+1")
+	if got := in.Get("x").Value; got != "1+1" {
+		t.Errorf("1+1 was read as %#v without WithExpr", got)
+	}
+}
@@ -0,0 +1,206 @@
+package input
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// NumberFormat is how a Pattern reads Int, Uint and Float tokens written
+// for people rather than as Go literals: 1,000.50 or 1.000,50, 1_000 and
+// 12%. Tokens it cannot read are still tried as literals, so 0x1f and 1e3
+// keep working.
+type NumberFormat struct {
+	// Group lists the runes that may separate groups of digits in the
+	// integer part. `_` always may. Groups are checked: after the first,
+	// each has 3 digits, or 2 before the last in Indian grouping.
+	Group string
+	// Decimal is the decimal mark, "." when empty.
+	Decimal string
+	// Percent lets a number end with %, which divides it by 100 and makes
+	// it a Float.
+	Percent bool
+}
+
+// LocaleNumbers returns the NumberFormat of the language tag, taking its
+// group separator and decimal mark from golang.org/x/text. Since spaces
+// split tokens, a locale that groups with a space only groups with `_`.
+func LocaleNumbers(tag language.Tag) (nf NumberFormat) {
+	nf.Percent = true
+	out := message.NewPrinter(tag).Sprint(number.Decimal(1234567.5))
+	var runs []string
+	var run strings.Builder
+	for _, r := range out {
+		if unicode.IsDigit(r) {
+			if run.Len() > 0 {
+				runs = append(runs, run.String())
+				run.Reset()
+			}
+			continue
+		}
+		run.WriteRune(r)
+	}
+	if run.Len() > 0 {
+		runs = append(runs, run.String())
+	}
+	if len(runs) == 0 {
+		return
+	}
+	nf.Decimal = runs[len(runs)-1]
+	if len(runs) > 1 {
+		if nf.Group = runs[0]; strings.TrimFunc(nf.Group, unicode.IsSpace) == "" {
+			nf.Group = ""
+		}
+	}
+	return
+}
+
+// WithNumbers reads the Int, Uint and Float tokens of the Pattern as nf
+// describes. A comma or dot of nf between two digits does not split a
+// number of nf, so 1,000.50 stays one token while 1,2 is still two.
+func WithNumbers(nf NumberFormat) Option {
+	return func(p *Pattern) {
+		p.numbers = &nf
+	}
+}
+
+// WithLocale is WithNumbers(LocaleNumbers(tag)).
+func WithLocale(tag language.Tag) Option {
+	return WithNumbers(LocaleNumbers(tag))
+}
+
+func (nf *NumberFormat) decimal() (d string) {
+	if d = nf.Decimal; d == "" {
+		d = "."
+	}
+	return
+}
+
+// joins reports whether the separator c between two digits belongs to a
+// number of nf rather than splitting two tokens.
+func (nf *NumberFormat) joins(c rune) bool {
+	return strings.ContainsRune(nf.Group, c) || nf.decimal() == string(c)
+}
+
+// parse reads raw as a number of nf. ok is false when it is not one.
+func (nf *NumberFormat) parse(raw string) (v any, ok bool) {
+	s := raw
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	pct := false
+	if nf.Percent && strings.HasSuffix(s, "%") {
+		s, pct = strings.TrimRightFunc(s[:len(s)-1], unicode.IsSpace), true
+	}
+	whole, frac, isFloat := strings.Cut(s, nf.decimal())
+	if isFloat && (frac == "" || strings.Trim(frac, "0123456789") != "") {
+		return
+	}
+	digits, ok := nf.groups(whole)
+	if !ok {
+		return
+	}
+	if !isFloat && !pct {
+		n, er := strconv.ParseInt(sign+digits, 10, 0)
+		if v, ok = int(n), er == nil; !ok {
+			v = nil
+		}
+		return
+	}
+	if isFloat {
+		digits += "." + frac
+	}
+	f, er := strconv.ParseFloat(sign+digits, 64)
+	if ok = er == nil; !ok {
+		return
+	}
+	if pct {
+		f /= 100
+	}
+	v = f
+	return
+}
+
+// groups strips the group separators from the integer part of a number and
+// checks the size of the groups.
+func (nf *NumberFormat) groups(whole string) (digits string, ok bool) {
+	var b strings.Builder
+	var sizes []int
+	checked := false
+	n := 0
+	for _, r := range whole {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+			n++
+			continue
+		case r == '_':
+		case strings.ContainsRune(nf.Group, r):
+			checked = true
+		default:
+			return
+		}
+		if n == 0 {
+			return
+		}
+		sizes, n = append(sizes, n), 0
+	}
+	if n == 0 {
+		return
+	}
+	sizes = append(sizes, n)
+	if checked {
+		for x, size := range sizes[1:] {
+			last := x == len(sizes)-2
+			if size != 3 && (last || size != 2) {
+				return
+			}
+		}
+		if sizes[0] > 3 {
+			return
+		}
+	}
+	digits, ok = b.String(), true
+	return
+}
+
+// split splits line into tokens as Split does, but keeps a number of the
+// Pattern's NumberFormat whole when a comma of it falls between two digits
+// and the tokens joined read as one number, so 1,000 is one token and 1,2
+// two.
+// When the Pattern has a Bytes or Duration placeholder, glue[x] is token x
+// and the next one read together when they are a number and a unit word
+// after a space, as in `2 days`, for such a placeholder to take as one
+// token. open is the quote or bracket left open in the last token, or 0.
+func (p *Pattern) split(line string) (raws, glue []string, open rune) {
+	spans, open := p.separators().spans(line)
+	for x := 0; x < len(spans); x++ {
+		s := spans[x]
+		if p.numbers != nil {
+			y := x
+			for y+1 < len(spans) && spans[y+1].start == spans[y].end+1 && p.numbers.joins(rune(line[spans[y].end])) &&
+				isDigit(line[spans[y].end-1]) && isDigit(line[spans[y].end+1]) {
+				y++
+			}
+			if _, ok := p.numbers.parse(line[s.start:spans[y].end]); ok {
+				x, s.end = y, spans[y].end
+			}
+		}
+		raws, glue = append(raws, line[s.start:s.end]), append(glue, "")
+		if p.units && x+1 < len(spans) && line[s.end] == ' ' && isUnitWord(line[spans[x+1].start:spans[x+1].end]) {
+			if _, er := strconv.ParseFloat(line[s.start:s.end], 64); er == nil {
+				glue[len(glue)-1] = line[s.start:spans[x+1].end]
+			}
+		}
+	}
+	return
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
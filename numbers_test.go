@@ -0,0 +1,77 @@
+package input
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestLocaleNumbers(t *testing.T) {
+	tests := []struct {
+		tag  language.Tag
+		want NumberFormat
+	}{
+		{language.English, NumberFormat{Group: ",", Decimal: ".", Percent: true}},
+		{language.German, NumberFormat{Group: ".", Decimal: ",", Percent: true}},
+		{language.French, NumberFormat{Decimal: ",", Percent: true}},
+	}
+	for _, tt := range tests {
+		if got := LocaleNumbers(tt.tag); got != tt.want {
+			t.Errorf("%v: got %+v, want %+v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestNumberFormatParse(t *testing.T) {
+	en := NumberFormat{Group: ",", Decimal: ".", Percent: true}
+	de := NumberFormat{Group: ".", Decimal: ",", Percent: true}
+	tests := []struct {
+		nf   NumberFormat
+		raw  string
+		want any
+	}{
+		{en, "1,000.50", 1000.5},
+		{en, "1,000", 1000},
+		{en, "1_000", 1000},
+		{en, "-1,234", -1234},
+		{en, "12,34,567", 1234567},
+		{en, "12%", 0.12},
+		{de, "1.000,50", 1000.5},
+		{de, "1.000", 1000},
+		{de, "12,5%", 0.125},
+		{en, "1,00", nil},
+		{en, "1234,567", nil},
+		{en, ",1", nil},
+		{en, "1.", nil},
+		{en, "x", nil},
+	}
+	for _, tt := range tests {
+		got, ok := tt.nf.parse(tt.raw)
+		if got != tt.want || ok != (tt.want != nil) {
+			t.Errorf("%+v %q: got %#v, %v, want %#v", tt.nf, tt.raw, got, ok, tt.want)
+		}
+	}
+}
+
+func TestNumberPlaceholders(t *testing.T) {
+	tests := []struct {
+		tag          language.Tag
+		format, line string
+		a, b         any
+	}{
+		{language.English, "pay ${a:Float} ${b:Int}", "pay 1,000.50, 1,000", 1000.5, 1000},
+		{language.English, "pay ${a:Float} ${b:Int}", "pay 12% 1_000", 0.12, 1000},
+		{language.German, "pay ${a:Float} ${b:Int}", "pay 1.000,50 2.000", 1000.5, 2000},
+		{language.English, "pay ${a:Int} ${b:Int}", "pay 1,2", 1, 2},
+		{language.English, "pay ${a:Int} ${b:Int}", "pay 1,000,000, 2", 1000000, 2},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format, WithLocale(tt.tag)).MatchString(tt.line)
+		if a, b := in.Get("a").Value, in.Get("b").Value; a != tt.a || b != tt.b || (err == nil) != (tt.a != nil) {
+			t.Errorf("%v %q: got %#v and %#v, %v, want %#v and %#v", tt.tag, tt.line, a, b, err, tt.a, tt.b)
+		}
+	}
+	if _, err := MustCompile("pay ${a:Float} ${b:Int}").MatchString("pay 1,000.50 3"); err == nil {
+		t.Error("1,000.50 read as one number without WithNumbers")
+	}
+}
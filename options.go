@@ -0,0 +1,42 @@
+package input
+
+// Option configures a Pattern when it is compiled.
+type Option func(p *Pattern)
+
+// WithFuzzy lets a literal word match a token that is at most maxDist edits
+// away from it, counting a swap of two neighbouring letters as one edit. A
+// fuzzy hit adds a partial score and a Suggestion, and is still reported as
+// a LiteralMismatch carrying the suggested word. 0 turns it off.
+func WithFuzzy(maxDist int) Option {
+	return func(p *Pattern) {
+		p.fuzzy = maxDist
+	}
+}
+
+// WithSummary sets the one-line description of the format shown by help
+// output.
+func WithSummary(text string) Option {
+	return func(p *Pattern) {
+		p.help().summary = text
+	}
+}
+
+// WithGroup files the format under group in the command lists written by
+// Router.WriteHelp.
+func WithGroup(group string) Option {
+	return func(p *Pattern) {
+		p.help().group = group
+	}
+}
+
+// WithArgDoc describes the placeholder called name in help output. Compile
+// fails when the format has no such placeholder.
+func WithArgDoc(name, text string) Option {
+	return func(p *Pattern) {
+		h := p.help()
+		if h.args == nil {
+			h.args = map[string]string{}
+		}
+		h.args[name] = text
+	}
+}
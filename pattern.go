@@ -0,0 +1,460 @@
+package input
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Pattern is a compiled format. The format is split and its placeholders
+// are parsed once by Compile, after which a Pattern is never modified, so a
+// single Pattern can be shared and matched from many goroutines at once.
+type Pattern struct {
+	format   string
+	elems    []element
+	fmtValue string
+	nvars    int
+	variadic bool
+	fuzzy    int
+	expr     *exprConfig
+	doc      *helpDoc
+	weights  *Weights
+	coercion Coercion
+	numbers  *NumberFormat
+	units    bool
+	flags    bool
+	shorts   map[rune]string
+	seps     *Separators
+	// root is the grammar of the format and prog the program it compiles
+	// to. flat is set when the format has no groups or compound words.
+	root *node
+	prog []op
+	flat bool
+}
+
+// element is one word of a format: either a literal or a placeholder.
+type element struct {
+	literal string
+	spec    *varSpec
+}
+
+// varSpec is the parsed form of a `${name?:Kind(constraint)=default}`
+// placeholder.
+type varSpec struct {
+	name     string
+	pos      int
+	kind     Kind
+	optional bool
+	variadic bool
+	hasDef   bool
+	def      any
+	coercion Coercion
+	// repeated is set for a variadic placeholder and one inside a
+	// repeated group, which collect a slice.
+	repeated bool
+
+	constraint *constraint
+}
+
+func (s *varSpec) newVar() (v *Var) {
+	v = &Var{
+		Name:         s.name,
+		Pos:          s.pos,
+		expectedKind: s.kind,
+		fmtValue:     KindFmtSymbol(s.kind),
+	}
+	return
+}
+
+// defaultVar is the Var of a placeholder that was left out of the input.
+func (s *varSpec) defaultVar() (v *Var) {
+	v = s.newVar()
+	if s.hasDef {
+		v.Value = s.def
+		v.Defaulted = true
+	}
+	return
+}
+
+// Compile parses format and returns a Pattern that can be matched against
+// input. It reports unbalanced `${`, quotes and brackets, empty words,
+// empty or duplicate names, unknown Kind names and defaults of the wrong
+// Kind.
+//
+// A placeholder is written `${name}`, `${name:Kind}`, `${name?:Kind}` when
+// it may be left out, or `${name:Kind=default}` when it falls back to a
+// default value; a placeholder with a default is always optional.
+// `${name...:Kind}` is variadic: it collects every token left over by the
+// rest of the format into a slice typed after Kind ([]string for String,
+// []int for Int, ...), so literals after it anchor the end of the line. A
+// variadic placeholder needs at least one token unless written
+// `${name?...:Kind}`, and a format may only have one.
+//
+// Words can be grouped to cover a family of commands in one format:
+//
+//	(add|remove) ${item} [to ${list}]
+//	set (${k}=${v})+
+//
+// `(a|b)` takes one of its alternatives, `[a]` or `(a)?` may be left out,
+// `(a)+` is taken once or more and `(a)*` any number of times; groups nest.
+// A word may mix literal text and placeholders, as `${k}=${v}` does, each
+// placeholder taking the shortest part of the token that lets the rest
+// match. A placeholder inside a repeated group collects a slice, as a
+// variadic one does. Every format is matched by following all the ways
+// through it at once and keeping the one that explains the line with the
+// fewest errors, preferring the first alternative, taking an optional
+// part over leaving it out and repeating as often as it goes; the time
+// this takes only grows with the tokens times the size of the format.
+// Input.Report lists the literal words that were taken. A repeated group
+// must take a token on each turn, and may not be only another repeated
+// group.
+func Compile(format string, opts ...Option) (p *Pattern, err error) {
+	if err = checkBraces(format); err != nil {
+		return
+	}
+	p = &Pattern{format: format}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if _, err = p.separators().Tokenize(format); err != nil {
+		p = nil
+		return
+	}
+	fp := &formatParser{p: p, seen: map[string]bool{}}
+	root, err := fp.seq(format)
+	if err != nil {
+		p = nil
+		return
+	}
+	if len(root.subs) == 0 {
+		p, err = nil, fmt.Errorf("input: empty format")
+		return
+	}
+	if err = root.checkReps(); err != nil {
+		p = nil
+		return
+	}
+	p.root, p.prog, p.flat = root, compile(root), !fp.grammar
+	p.elems = fp.elems
+	matchers := make([]string, 0, len(p.elems))
+	for _, e := range p.elems {
+		if e.spec == nil {
+			matchers = append(matchers, e.literal)
+		} else {
+			matchers = append(matchers, KindFmtSymbol(e.spec.kind))
+		}
+	}
+	p.fmtValue = strings.Join(matchers, " ")
+	if err = p.checkShorts(); err != nil {
+		p = nil
+		return
+	}
+	if p.doc != nil {
+		for name := range p.doc.args {
+			if !fp.seen[name] {
+				p, err = nil, fmt.Errorf("input: description for unknown placeholder %q", name)
+				return
+			}
+		}
+	}
+	return
+}
+
+// MustCompile is like Compile but panics if the format cannot be parsed.
+func MustCompile(format string, opts ...Option) (p *Pattern) {
+	p, err := Compile(format, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// evalAs reads a token of input bound for a placeholder of Kind k as a
+// literal, or as an expression when the Pattern was compiled WithExpr,
+// falling back to the unquoted token. Numeric kinds first try the
+// Pattern's NumberFormat, and an expression must type check as k. scope
+// returns the names the expression may use on top of the Pattern's env; it
+// is only called when raw is not a literal.
+func (p *Pattern) evalAs(raw string, k Kind, scope func() map[string]any) (v any) {
+	if p.numbers != nil && (k == Int || k == Uint || k == Float) {
+		var ok bool
+		if v, ok = p.numbers.parse(raw); ok {
+			if n, isInt := v.(int); isInt && k == Float {
+				v = float64(n)
+			}
+			return
+		}
+	}
+	var err error
+	if v, err = ParseLiteral(raw); err == nil {
+		return
+	}
+	if p.expr != nil {
+		env := p.expr.env
+		if scope != nil {
+			env = scope()
+		}
+		if v, err = p.expr.eval(raw, k, env); err == nil {
+			return
+		}
+	}
+	v = unquote(raw)
+	return
+}
+
+func (p *Pattern) String() string {
+	return p.format
+}
+
+// Match reads r to the end and matches it against the pattern, returning a
+// new Input holding the captured vars.
+func (p *Pattern) Match(r io.Reader) (i *Input, err error) {
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	i, err = p.MatchString(string(out))
+	return
+}
+
+// MatchString is Match for a line that is already in memory. The returned
+// Input is never nil; when some tokens do not match err is a *MatchError
+// and the Input holds whatever could be captured.
+func (p *Pattern) MatchString(line string) (i *Input, err error) {
+	i = &Input{}
+	err = p.read(i, line, nil)
+	return
+}
+
+// MatchEnv is MatchString with env added to the names expressions may use,
+// on top of the ones given to WithEnv. It only matters for a Pattern
+// compiled WithExpr or WithEnv.
+func (p *Pattern) MatchEnv(line string, env map[string]any) (i *Input, err error) {
+	i = &Input{}
+	err = p.read(i, line, env)
+	return
+}
+
+// read matches line against p and stores the result in i. With WithFlags,
+// the flags are taken out of line first and the tokens left are matched.
+// A quote or bracket left open is reported on top of how the tokens
+// matched, and costs its token the points of an extra one.
+func (p *Pattern) read(i *Input, line string, env map[string]any) (err error) {
+	raws, glue, open := p.split(line)
+	best := p.matchGrammar(p.takeFlags(raws, glue), env)
+	if open != 0 {
+		last := len(raws) - 1
+		best.errs = append([]*TokenError{{Reason: Unterminated, Pos: last, Literal: string(open), Token: raws[last]}}, best.errs...)
+		sort.SliceStable(best.errs, func(x, y int) bool { return best.errs[x].Pos < best.errs[y].Pos })
+		best.report.add(SlotScore{Pos: last, Token: raws[last], Max: math.Max(best.w.Literal, best.w.Kind)})
+	}
+	err = best.store(i, line)
+	return
+}
+
+// store stores a, the result of matching line, in i.
+func (a *alignment) store(i *Input, line string) (err error) {
+	i.pattern = a.p
+	i.line = line
+	i.fmtValue = a.p.fmtValue
+	i.vars = a.vars
+	i.suggestions = a.suggestions
+	i.report = &a.report
+	i.score = a.report.Score
+	i.err = nil
+	if len(a.errs) > 0 {
+		err = &MatchError{Format: a.p.format, Line: line, Errors: a.errs}
+		i.err = err
+	}
+	return
+}
+
+// alignment is the result of matching tokens against a Pattern.
+type alignment struct {
+	p           *Pattern
+	env         map[string]any
+	vars        map[string]*Var
+	errs        []*TokenError
+	suggestions []Suggestion
+	w           Weights
+	report      ScoreReport
+}
+
+func (a *alignment) fail(te *TokenError) {
+	a.errs = append(a.errs, te)
+}
+
+func (a *alignment) eval(raw string) (v any) {
+	v = a.p.evalAs(raw, Any, a.scope)
+	return
+}
+
+// scope is the scope of an expression: the Pattern's env, the env of the
+// match and the vars captured so far, in increasing order of precedence.
+func (a *alignment) scope() (env map[string]any) {
+	env = make(map[string]any, len(a.p.expr.env)+len(a.env)+len(a.vars))
+	for n, v := range a.p.expr.env {
+		env[n] = v
+	}
+	for n, v := range a.env {
+		env[n] = v
+	}
+	for n, v := range a.vars {
+		if v.Value != nil {
+			env[n] = v.Value
+		}
+	}
+	return
+}
+
+// parse reads raw as a value of the placeholder's Kind, evaluating it with
+// eval unless the Kind parses its own tokens, and converting it as the
+// Pattern's Coercion allows. got is the Kind raw evaluates to on its own,
+// for error reports.
+func (s *varSpec) parse(raw string, eval func(string) any) (val any, got Kind, coerced bool, err error) {
+	if e, ok := kindDef(s.kind); ok {
+		if val, err = e.parse(raw); err != nil {
+			got = kindOf(eval(raw))
+		} else {
+			got = s.kind
+		}
+		return
+	}
+	val = eval(raw)
+	got = kindOf(val)
+	if str, ok := val.(string); ok && s.kind == RGBHex {
+		// Colors are always written as text: #fff, rgb(...) or a name.
+		val, err = ParseColor(str)
+		return
+	}
+	if s.kind == Bytes || s.kind == Duration {
+		// Sizes and durations are read from the token, so that a bare
+		// number is taken in the base unit.
+		str, ok := val.(string)
+		if !ok {
+			str = raw
+		}
+		if s.kind == Bytes {
+			val, err = ParseBytes(str)
+		} else {
+			val, err = ParseDuration(str)
+		}
+		if err != nil {
+			val = nil
+		}
+		return
+	}
+	val, coerced, err = coerce(s.kind, val, s.coercion)
+	return
+}
+
+// parseVar parses a placeholder word:
+//
+//	${name[?][...][:Kind[(constraint)] | :/regexp/][=default]}
+func parseVar(word string, pos int, mode Coercion) (spec *varSpec, err error) {
+	body := strings.TrimSuffix(strings.TrimPrefix(word, "${"), "}")
+	spec = &varSpec{pos: pos, kind: Any, coercion: mode}
+	n := strings.IndexAny(body, ":=")
+	if n < 0 {
+		n = len(body)
+	}
+	name, rest := body[:n], body[n:]
+	if strings.HasPrefix(rest, ":") {
+		if rest, err = spec.parseKind(rest[1:]); err != nil {
+			spec, err = nil, fmt.Errorf("input: %v in %s", err, word)
+			return
+		}
+	}
+	var def string
+	if strings.HasPrefix(rest, "=") {
+		def, spec.optional, spec.hasDef = rest[1:], true, true
+	} else if rest != "" {
+		spec, err = nil, fmt.Errorf("input: unexpected %q in %s", rest, word)
+		return
+	}
+	if strings.HasSuffix(name, "?") {
+		name, spec.optional = strings.TrimSuffix(name, "?"), true
+	}
+	if strings.HasSuffix(name, "...") {
+		name, spec.variadic = strings.TrimSuffix(name, "..."), true
+	}
+	if strings.HasSuffix(name, "?") {
+		name, spec.optional = strings.TrimSuffix(name, "?"), true
+	}
+	if spec.name = name; name == "" {
+		spec, err = nil, fmt.Errorf("input: missing placeholder name in %s", word)
+		return
+	}
+	if spec.hasDef {
+		var er error
+		if spec.def, _, _, er = spec.parse(def, evalArg); er == nil {
+			er = spec.check(spec.def, def)
+		}
+		if er != nil {
+			spec, err = nil, fmt.Errorf("input: default %q of %s: %v", def, name, er)
+		}
+	}
+	return
+}
+
+// parseKind reads the Kind and constraint that follow the colon of a
+// placeholder and returns what is left of it.
+func (s *varSpec) parseKind(str string) (rest string, err error) {
+	if strings.HasPrefix(str, "/") {
+		s.kind = String
+		s.constraint, rest, err = parseRegexp(str)
+		return
+	}
+	n := strings.IndexAny(str, "(=")
+	if n < 0 {
+		n = len(str)
+	}
+	kind, ok := lookupKind(str[:n])
+	if !ok {
+		err = fmt.Errorf("unknown kind %q", str[:n])
+		return
+	}
+	s.kind, rest = kind, str[n:]
+	if strings.HasPrefix(rest, "(") {
+		end := strings.Index(rest, ")")
+		if end < 0 {
+			err = fmt.Errorf("unclosed constraint %q", rest)
+			return
+		}
+		if s.constraint, err = parseConstraint(rest[1:end]); err != nil {
+			return
+		}
+		rest = rest[end+1:]
+	}
+	return
+}
+
+// checkBraces reports a `${` that is never closed.
+func checkBraces(format string) (err error) {
+	for x := 0; x < len(format); x++ {
+		if !strings.HasPrefix(format[x:], "${") {
+			continue
+		}
+		lvl := 0
+		end := -1
+		for y := x + 1; y < len(format) && end < 0; y++ {
+			switch format[y] {
+			case '{':
+				lvl++
+			case '}':
+				if lvl--; lvl == 0 {
+					end = y
+				}
+			}
+		}
+		if end < 0 {
+			err = fmt.Errorf("input: unbalanced `${` at offset %d in %q", x, format)
+			return
+		}
+		x = end
+	}
+	return
+}
@@ -0,0 +1,165 @@
+package input
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOptionalPlaceholders(t *testing.T) {
+	tests := []struct {
+		format string
+		opts   []Option
+		line   string
+		want   map[string]any
+		ok     bool
+	}{
+		{"ls ${n?:Int} ${path?:String}", nil, "ls /tmp", map[string]any{"n": nil, "path": "/tmp"}, true},
+		{"ls ${n?:Int} ${path?:String}", nil, "ls 5", map[string]any{"n": 5, "path": nil}, true},
+		{"ls ${n?:Int} ${path?:String}", nil, "ls 5 /tmp", map[string]any{"n": 5, "path": "/tmp"}, true},
+		{"ls ${n?:Int} ${path?:String}", nil, "ls", map[string]any{"n": nil, "path": nil}, true},
+		{"ls ${n:Int=10} ${path:String=\".\"}", nil, "ls /tmp", map[string]any{"n": 10, "path": "/tmp"}, true},
+		{"ls ${n:Int=10} ${path:String=\".\"}", nil, "ls", map[string]any{"n": 10, "path": "."}, true},
+		{"${s?:String} ${n?:Int}", []Option{WithCoercion(Lenient)}, "5", map[string]any{"s": nil, "n": 5}, true},
+		{"deploy ${env} ${port:Int=80}", nil, "deploy prod x", nil, false},
+		{"deploy ${env} ${port:Int=80}", nil, "deploy prod 1 2", nil, false},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format, tt.opts...).MatchString(tt.line)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s %q: got error %v", tt.format, tt.line, err)
+			continue
+		}
+		for name, want := range tt.want {
+			if got := in.Get(name).Value; !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %q: %s is %#v, want %#v", tt.format, tt.line, name, got, want)
+			}
+		}
+	}
+}
+
+func TestDefaulted(t *testing.T) {
+	p := MustCompile("deploy ${env} ${port:Int=80}")
+	in, _ := p.MatchString("deploy prod")
+	if v := in.Get("port"); !v.Defaulted || v.Value != 80 {
+		t.Errorf("port is %v, Defaulted %v", v.Value, v.Defaulted)
+	}
+	in, _ = p.MatchString("deploy prod 8080")
+	if v := in.Get("port"); v.Defaulted || v.Value != 8080 {
+		t.Errorf("port is %v, Defaulted %v", v.Value, v.Defaulted)
+	}
+	for _, format := range []string{"${port:Int=x}", "${port:Int(1..9)=10}"} {
+		if _, err := Compile(format); err == nil {
+			t.Errorf("%q compiled with a bad default", format)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		format string
+		err    string
+	}{
+		{"${command:String}: ${args:Int}, name:${n:String}", ""},
+		{"go ${dir}", ""},
+		{"", "input: empty format"},
+		{"go ${dir", "input: unbalanced `${` at offset 3 in \"go ${dir\""},
+		{"go ${dir:Vector}", `input: unknown kind "Vector" in ${dir:Vector}`},
+		{"go ${}", "input: missing placeholder name in ${}"},
+		{"go ${dir} ${dir}", `input: duplicate placeholder "dir"`},
+		{"go ${n:Int=x}", `input: default "x" of n: not of kind Int`},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.format)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%q: %v", tt.format, err)
+		case tt.err != "" && (err == nil || err.Error() != tt.err):
+			t.Errorf("%q: got error %v, want %s", tt.format, err, tt.err)
+		case err != nil && p != nil:
+			t.Errorf("%q: got a Pattern with an error", tt.format)
+		}
+	}
+}
+
+func TestMustCompile(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompile did not panic on a bad format")
+		}
+	}()
+	MustCompile("go ${dir")
+}
+
+func TestMatch(t *testing.T) {
+	p := MustCompile("${command:String}: ${args:Int}, name:${n:String}")
+	tests := []struct {
+		line string
+		want map[string]any
+	}{
+		{"run: 5, name:bob", map[string]any{"command": "run", "args": 5, "n": "bob"}},
+		{"stop: 10, name:al", map[string]any{"command": "stop", "args": 10, "n": "al"}},
+	}
+	for _, tt := range tests {
+		in, err := p.Match(strings.NewReader(tt.line))
+		if err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+			continue
+		}
+		if got := in.All(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestMatchConcurrent(t *testing.T) {
+	p := MustCompile("add ${n:Int} to ${list}")
+	var wg sync.WaitGroup
+	for x := 0; x < 8; x++ {
+		wg.Add(1)
+		go func(x int) {
+			defer wg.Done()
+			in, err := p.MatchString(fmt.Sprintf("add %d to l%d", x, x))
+			if err != nil || in.Get("n").Value != x || in.Get("list").Value != fmt.Sprintf("l%d", x) {
+				t.Errorf("%d: got %v, %v", x, in.All(), err)
+			}
+		}(x)
+	}
+	wg.Wait()
+}
+
+func TestVariadic(t *testing.T) {
+	tests := []struct {
+		format, line string
+		want         map[string]any
+		err          string
+	}{
+		{"tag ${id:Int} ${labels...:String}", "tag 5 a b c", map[string]any{"id": 5, "labels": []string{"a", "b", "c"}}, ""},
+		{"tag ${id:Int} ${labels?...:String}", "tag 5", map[string]any{"id": 5, "labels": []string{}}, ""},
+		{"sum ${ns...:Int}", "sum 1 2 3", map[string]any{"ns": []int{1, 2, 3}}, ""},
+		{"copy ${files...} to ${dest}", "copy a b to c", map[string]any{"files": []any{"a", "b"}, "dest": "c"}, ""},
+		{"tag ${id:Int} ${labels...:String}", "tag 5", nil, "token 2: missing labels (String)"},
+		{"sum ${ns...:Int}", "sum 1 x 3", map[string]any{"ns": []int{1, 3}}, `token 2: ns expects Int, got String "x"`},
+		{"copy ${files...} to ${dest}", "copy a to", nil, "token 3: missing dest (Any)"},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format).MatchString(tt.line)
+		if me, ok := err.(*MatchError); tt.err != "" && (!ok || me.Errors[0].Error() != tt.err) {
+			t.Errorf("%s %q: got error %v, want %s", tt.format, tt.line, err, tt.err)
+		} else if tt.err == "" && err != nil {
+			t.Errorf("%s %q: %v", tt.format, tt.line, err)
+		}
+		for name, want := range tt.want {
+			if got := in.Get(name).Value; !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %q: %s is %#v, want %#v", tt.format, tt.line, name, got, want)
+			}
+		}
+	}
+	for _, format := range []string{"${a...} ${b...}", "go ${a...}x"} {
+		if _, err := Compile(format); err == nil {
+			t.Errorf("%q compiled", format)
+		}
+	}
+}
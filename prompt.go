@@ -0,0 +1,222 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prompter completes a partly typed line by asking for the placeholders it
+// lacks. It reads answers from a plain reader and writes prompts to a plain
+// writer, so a terminal is not needed.
+type Prompter struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+func NewPrompter(r io.Reader, w io.Writer) (pr *Prompter) {
+	pr = &Prompter{r: bufio.NewReader(r), w: w}
+	return
+}
+
+// Prompt matches line against p, then asks in order for every placeholder
+// that is missing or whose token was rejected. Each prompt shows the Kind,
+// the constraint and the default of the placeholder, and is repeated until
+// the answer is valid. An empty answer leaves an optional placeholder out,
+// taking its default. The answers of a variadic placeholder, or of one in a
+// repeated group, are split into tokens as a line is. Once every answer is
+// in, line is matched again with the answers in place of the rejected
+// tokens; answers are scored as slots without a position in line.
+//
+// Prompting cannot fix a mistyped, extra or missing literal word, a wrong
+// flag or a quote or bracket left open: Prompt then returns the Input and
+// *MatchError of line without asking anything. When the reader runs out
+// before every placeholder is answered, err wraps io.ErrUnexpectedEOF.
+func (pr *Prompter) Prompt(p *Pattern, line string) (in *Input, err error) {
+	in = &Input{}
+	mErr := p.read(in, line, nil)
+	if mErr == nil {
+		return
+	}
+	bad := map[string]*TokenError{}
+	for _, te := range mErr.(*MatchError).Errors {
+		switch {
+		case te.Reason == LiteralMismatch, te.Reason == ExtraToken, te.Reason == UnknownFlag, te.Reason == RepeatedFlag, te.Reason == Unterminated,
+			te.Reason == MissingToken && te.Name == "":
+			err = mErr
+			return
+		case te.Name != "" && bad[te.Name] == nil:
+			bad[te.Name] = te
+		}
+	}
+	a := &alignment{p: p, vars: in.vars, w: p.scoreWeights()}
+	a.report.Weights = a.w
+	answers := map[string][]string{}
+	for _, e := range p.elems {
+		if e.spec == nil || bad[e.spec.name] == nil {
+			continue
+		}
+		te := bad[e.spec.name]
+		if te.Reason != MissingToken {
+			if _, err = fmt.Fprintf(pr.w, "%s\n", problem(te)); err != nil {
+				return
+			}
+		}
+		if answers[e.spec.name], err = pr.ask(a, e.spec); err != nil {
+			in.err = err
+			return
+		}
+	}
+	raws, glue, _ := p.split(line)
+	fs := p.takeFlags(raws, glue).answered(p, in.report.Slots, answers)
+	err = p.matchGrammar(fs, nil).store(in, line)
+	return
+}
+
+// ask prompts for s until it gets a valid answer, stores it in a and
+// returns its tokens. The tokens are nil when an optional placeholder is
+// left out.
+func (pr *Prompter) ask(a *alignment, s *varSpec) (raws []string, err error) {
+	for {
+		if _, err = io.WriteString(pr.w, promptFor(s, a.p.separators())); err != nil {
+			return
+		}
+		answer, er := pr.r.ReadString('\n')
+		if er != nil && (!errors.Is(er, io.EOF) || answer == "") {
+			if err = er; errors.Is(er, io.EOF) {
+				err = fmt.Errorf("input: no answer for %s: %w", s.name, io.ErrUnexpectedEOF)
+			}
+			return
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" && s.optional {
+			a.vars[s.name] = s.defaultVar()
+			return
+		}
+		if answer == "" {
+			if _, err = fmt.Fprintf(pr.w, "  %s is required\n", s.name); err != nil {
+				return
+			}
+			continue
+		}
+		raws = []string{answer}
+		if s.repeated {
+			raws = a.p.splitAnswer(s, answer)
+		}
+		a.errs = nil
+		var caps []capture
+		for _, raw := range raws {
+			c, _, te := a.capture(s, raw, -1, a.scope)
+			if te != nil {
+				a.fail(te)
+				continue
+			}
+			caps = append(caps, c)
+		}
+		v := a.gather(s, caps, len(a.errs) > 0)
+		if len(a.errs) == 0 {
+			a.vars[s.name] = v
+			return
+		}
+		for _, te := range a.errs {
+			if _, err = fmt.Fprintf(pr.w, "%s\n", problem(te)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// splitAnswer splits the answer for the repeated placeholder s into
+// tokens as a line is, keeping a number and its unit together when s takes
+// them as one.
+func (p *Pattern) splitAnswer(s *varSpec, answer string) (raws []string) {
+	toks, glue, _ := p.split(answer)
+	for x := 0; x < len(toks); x++ {
+		if glue[x] != "" && s.takesUnit() {
+			raws = append(raws, glue[x])
+			x++
+			continue
+		}
+		raws = append(raws, toks[x])
+	}
+	return
+}
+
+// answered is fs with the placeholders in answers given their answers
+// instead of their tokens in the line, found from the slots of matching
+// it.
+func (fs *flagSet) answered(p *Pattern, slots []SlotScore, answers map[string][]string) (out *flagSet) {
+	drop := map[int]bool{}
+	for _, ss := range slots {
+		if _, ok := answers[ss.Name]; ok && ss.Pos >= 0 {
+			drop[ss.Pos] = true
+		}
+	}
+	out = &flagSet{n: fs.n, glue: fs.glue}
+	for x, raw := range fs.rest {
+		if !drop[fs.at[x]] {
+			out.rest, out.at = append(out.rest, raw), append(out.at, fs.at[x])
+		}
+	}
+	for _, fv := range fs.vals {
+		if _, ok := answers[fv.spec.name]; !ok {
+			out.vals = append(out.vals, fv)
+		}
+	}
+	for _, e := range p.elems {
+		if e.spec == nil {
+			continue
+		}
+		raws, ok := answers[e.spec.name]
+		if !ok {
+			continue
+		}
+		fv := &flagValue{spec: e.spec, raws: raws, at: make([]int, len(raws))}
+		for x := range fv.at {
+			fv.at[x] = -1
+		}
+		out.vals = append(out.vals, fv)
+	}
+	for x, te := range fs.errs {
+		if _, ok := answers[te.Name]; !ok {
+			out.errs, out.slots = append(out.errs, te), append(out.slots, fs.slots[x])
+		}
+	}
+	return
+}
+
+// promptFor is the question asked for s, e.g. `port (Int, 1..65535) [8080]: `.
+func promptFor(s *varSpec, sep Separators) string {
+	notes := KindString(s.kind)
+	if s.repeated {
+		notes += "..."
+	}
+	if s.constraint != nil {
+		if s.constraint.enum != nil {
+			notes += ", one of " + strings.Join(s.constraint.enum, ", ")
+		} else {
+			notes += ", " + s.constraint.text
+		}
+	}
+	q := fmt.Sprintf("%s (%s)", s.name, notes)
+	if s.hasDef {
+		def, err := renderKind(s.kind, s.def, sep)
+		if err != nil {
+			def = fmt.Sprint(s.def)
+		}
+		q += " [" + def + "]"
+	}
+	return q + ": "
+}
+
+// problem explains why te's token was rejected, without its position.
+func problem(te *TokenError) (str string) {
+	if te.Err != nil {
+		str = fmt.Sprintf("  %s: %q: %v", te.Name, te.Token, te.Err)
+	} else {
+		str = "  " + te.Error()
+	}
+	return
+}
@@ -0,0 +1,87 @@
+package input
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	p := MustCompile("copy ${src} ${n:Int(1..9)} ${files...:String}")
+	tests := []struct {
+		line, answers string
+		want          map[string]any
+		asked         string
+	}{
+		{"copy", "a\nx\n12\n3\nf g\n", map[string]any{"src": "a", "n": 3, "files": []string{"f", "g"}},
+			"src (Any): n (Int, 1..9):   n: \"x\": not of kind Int\nn (Int, 1..9):   n: \"12\": value 12 is out of range 1..9\nn (Int, 1..9): files (String...): "},
+		{"copy a x", "4\nf\n", map[string]any{"src": "a", "n": 4, "files": []string{"f"}},
+			"  n: \"x\": not of kind Int\nn (Int, 1..9): files (String...): "},
+		{"copy a 1 f", "", map[string]any{"src": "a", "n": 1, "files": []string{"f"}}, ""},
+	}
+	for _, tt := range tests {
+		var w strings.Builder
+		in, err := NewPrompter(strings.NewReader(tt.answers), &w).Prompt(p, tt.line)
+		if err != nil || !reflect.DeepEqual(in.All(), tt.want) || w.String() != tt.asked {
+			t.Errorf("%q: got %v, %v after asking %q, want %v after %q", tt.line, in.All(), err, w.String(), tt.want, tt.asked)
+		}
+	}
+}
+
+func TestPromptOptional(t *testing.T) {
+	p := MustCompile("deploy ${env:String(dev|prod)} ${port:Int=80}")
+	var w strings.Builder
+	in, err := NewPrompter(strings.NewReader("qa\nprod\n"), &w).Prompt(p, "deploy")
+	if err != nil || in.Get("env").Value != "prod" || in.Get("port").Value != 80 || !in.Get("port").Defaulted {
+		t.Errorf("got %v, %v", in.All(), err)
+	}
+	if want := "env (String, one of dev, prod):   env: \"qa\": \"qa\" is not one of dev, prod\nenv (String, one of dev, prod): "; w.String() != want {
+		t.Errorf("asked %q, want %q", w.String(), want)
+	}
+}
+
+func TestPromptGivesUp(t *testing.T) {
+	p := MustCompile("copy ${src} ${n:Int(1..9)}")
+	tests := []struct {
+		format, line, answers string
+		reason                Reason
+	}{
+		{"", "cpoy a 1", "", LiteralMismatch},
+		{"", "copy a 1 2", "", ExtraToken},
+		{"", `copy "a`, "", Unterminated},
+		{"deploy ${env} now", "deploy prod", "x\n", MissingToken},
+		{"set ${k}=${v}", "set", "x\ny\n", MissingToken},
+	}
+	for _, tt := range tests {
+		var w strings.Builder
+		q := p
+		if tt.format != "" {
+			q = MustCompile(tt.format)
+		}
+		_, err := NewPrompter(strings.NewReader(tt.answers), &w).Prompt(q, tt.line)
+		if me, ok := err.(*MatchError); !ok || me.Errors[0].Reason != tt.reason || w.Len() != 0 {
+			t.Errorf("%q: got %v after asking %q", tt.line, err, w.String())
+		}
+	}
+	if _, err := NewPrompter(strings.NewReader("\n"), io.Discard).Prompt(p, "copy a"); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v when the answers run out", err)
+	}
+}
+
+func TestPromptRepeatedGroup(t *testing.T) {
+	var w strings.Builder
+	in, err := NewPrompter(strings.NewReader("x y\n"), &w).Prompt(MustCompile("tag (${l:String})+"), "tag")
+	if err != nil || !reflect.DeepEqual(in.Get("l").Value, []string{"x", "y"}) || w.String() != "l (String...): " {
+		t.Errorf("got %#v, %v after asking %q", in.Get("l").Value, err, w.String())
+	}
+}
+
+func TestPromptFlags(t *testing.T) {
+	var w strings.Builder
+	in, err := NewPrompter(strings.NewReader("4\n"), &w).Prompt(MustCompile("copy ${src} ${n:Int}", WithFlags()), "copy --n x a")
+	if err != nil || in.Get("src").Value != "a" || in.Get("n").Value != 4 || in.Report().Slots[len(in.Report().Slots)-1].Pos != -1 {
+		t.Errorf("got %v, %v after asking %q", in.All(), err, w.String())
+	}
+}
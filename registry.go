@@ -0,0 +1,118 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// firstCustomKind is the Kind given to the first kind registered with
+// RegisterKind; the ones below it are kept for built-in kinds.
+const firstCustomKind Kind = 64
+
+// KindDef describes a Kind registered with RegisterKind. Only Parse is
+// required.
+type KindDef struct {
+	// Parse turns a raw token into a value of the kind, or reports why the
+	// token is not one.
+	Parse func(token string) (any, error)
+	// Validate checks a parsed value further, e.g. against a remote list.
+	Validate func(v any) error
+	// Format writes a value back as a token. fmt.Sprint is used when nil.
+	Format func(v any) string
+	// Zero returns the zero value of the kind, for KindValue.
+	Zero func() any
+	// Complete lists candidate tokens starting with prefix.
+	Complete func(prefix string) []string
+}
+
+type kindEntry struct {
+	name string
+	def  KindDef
+}
+
+var registry = struct {
+	sync.RWMutex
+	byName map[string]Kind
+	byKind map[Kind]*kindEntry
+	next   Kind
+}{
+	byName: map[string]Kind{},
+	byKind: map[Kind]*kindEntry{},
+	next:   firstCustomKind,
+}
+
+// RegisterKind adds a Kind named name, so that `${x:name}` placeholders
+// parse their token with def. It fails when name is taken or the registry
+// is full.
+func RegisterKind(name string, def KindDef) (k Kind, err error) {
+	if name == "" || def.Parse == nil {
+		err = errors.New("input: a kind needs a name and a Parse func")
+		return
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := kindNames[name]; ok {
+		err = fmt.Errorf("input: kind %q is built in", name)
+		return
+	}
+	if _, ok := registry.byName[name]; ok {
+		err = fmt.Errorf("input: kind %q is already registered", name)
+		return
+	}
+	if registry.next == 0 {
+		err = fmt.Errorf("input: no room left to register kind %q", name)
+		return
+	}
+	k = registry.next
+	registry.next++
+	registry.byName[name] = k
+	registry.byKind[k] = &kindEntry{name: name, def: def}
+	return
+}
+
+// MustRegisterKind is like RegisterKind but panics on error.
+func MustRegisterKind(name string, def KindDef) (k Kind) {
+	k, err := RegisterKind(name, def)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+func customKind(name string) (k Kind, ok bool) {
+	registry.RLock()
+	k, ok = registry.byName[name]
+	registry.RUnlock()
+	return
+}
+
+func kindDef(k Kind) (e *kindEntry, ok bool) {
+	if k < firstCustomKind {
+		return
+	}
+	registry.RLock()
+	e, ok = registry.byKind[k]
+	registry.RUnlock()
+	return
+}
+
+// parse runs Parse and then Validate on token.
+func (e *kindEntry) parse(token string) (v any, err error) {
+	if v, err = e.def.Parse(token); err != nil {
+		return
+	}
+	if e.def.Validate != nil {
+		err = e.def.Validate(v)
+	}
+	return
+}
+
+func (e *kindEntry) format(v any) (str string) {
+	if e.def.Format != nil {
+		str = e.def.Format(v)
+	} else {
+		str = fmt.Sprint(v)
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package input
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testEnvironment is registered once for the whole test binary, since the
+// registry cannot forget a kind.
+var testEnvironment = MustRegisterKind("TestEnvironment", KindDef{
+	Parse: func(s string) (any, error) {
+		if s != "dev" && s != "prod" {
+			return nil, errors.New("not an environment")
+		}
+		return strings.ToUpper(s), nil
+	},
+	Validate: func(v any) error {
+		if v == "PROD" {
+			return errors.New("prod is frozen")
+		}
+		return nil
+	},
+	Format: func(v any) string { return strings.ToLower(v.(string)) },
+	Zero:   func() any { return "DEV" },
+	Complete: func(prefix string) (out []string) {
+		for _, e := range []string{"dev", "prod"} {
+			if strings.HasPrefix(e, prefix) {
+				out = append(out, e)
+			}
+		}
+		return
+	},
+})
+
+func TestRegisterKind(t *testing.T) {
+	if testEnvironment < firstCustomKind || KindString(testEnvironment) != "TestEnvironment" || StringToKind("TestEnvironment") != testEnvironment {
+		t.Errorf("kind %d is called %q", testEnvironment, KindString(testEnvironment))
+	}
+	if _, ok := KindValue(testEnvironment).(*string); !ok {
+		t.Errorf("KindValue is %T", KindValue(testEnvironment))
+	}
+	parse := func(s string) (any, error) { return s, nil }
+	tests := []struct {
+		name string
+		def  KindDef
+		err  string
+	}{
+		{"TestEnvironment", KindDef{Parse: parse}, `input: kind "TestEnvironment" is already registered`},
+		{"Int", KindDef{Parse: parse}, `input: kind "Int" is built in`},
+		{"NoParse", KindDef{}, "input: a kind needs a name and a Parse func"},
+		{"", KindDef{Parse: parse}, "input: a kind needs a name and a Parse func"},
+	}
+	for _, tt := range tests {
+		if _, err := RegisterKind(tt.name, tt.def); err == nil || err.Error() != tt.err {
+			t.Errorf("%q: got error %v, want %s", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestCustomKindPlaceholder(t *testing.T) {
+	p := MustCompile("deploy ${env:TestEnvironment}")
+	tests := []struct {
+		line string
+		want any
+		err  string
+	}{
+		{"deploy dev", "DEV", ""},
+		{"deploy prod", nil, `token 1: env expects TestEnvironment, got String "prod": prod is frozen`},
+		{"deploy x", nil, `token 1: env expects TestEnvironment, got String "x": not an environment`},
+	}
+	for _, tt := range tests {
+		in, err := p.MatchString(tt.line)
+		if me, ok := err.(*MatchError); tt.err != "" && (!ok || me.Errors[0].Error() != tt.err) {
+			t.Errorf("%q: got error %v, want %s", tt.line, err, tt.err)
+		} else if tt.err == "" && err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+		}
+		if got := in.Get("env").Value; got != tt.want {
+			t.Errorf("%q: got %#v, want %#v", tt.line, got, tt.want)
+		}
+	}
+	if line, err := p.Render(map[string]any{"env": "DEV"}); err != nil || line != "deploy dev" {
+		t.Errorf("Render: got %q, %v", line, err)
+	}
+	r := NewRouter()
+	r.Handle("deploy ${env:TestEnvironment}", nil)
+	var got []string
+	for _, c := range r.Complete("deploy ", 7) {
+		got = append(got, c.Text)
+	}
+	if !reflect.DeepEqual(got, []string{"dev", "prod"}) {
+		t.Errorf("Complete: got %v", got)
+	}
+}
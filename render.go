@@ -0,0 +1,509 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Render writes values back into the shape of the format, so that matching
+// the result against p captures the same values again. values is either a
+// map[string]any keyed by placeholder name or a struct (or pointer to one)
+// whose fields are named as for Input.Bind.
+//
+// Literal words and separators are copied from the format. Each value is
+// written with the verb KindFmtSymbol gives its Kind, except floats, which
+// use the shortest form that reads back as the same float. Strings are
+// quoted when they would otherwise split into several tokens or read back
+// as another Kind. An optional placeholder without a value is left out
+// together with the separators before it; when a later optional one has a
+// value, its default is written instead. Render fails rather than return a
+// line that would not read back as values.
+//
+// A format with groups is written with a space between words, or the
+// first separator when space is not one of them. An optional group is
+// written when a placeholder in it has a value, the first alternative
+// whose placeholders all have one is taken, and a repeated group is
+// written once for each value of the slices of its placeholders.
+func (p *Pattern) Render(values any) (line string, err error) {
+	vals, err := renderValues(values)
+	if err != nil {
+		return
+	}
+	if !p.flat {
+		var words []string
+		if words, err = p.renderNode(p.root, vals, -1, words); err != nil {
+			return
+		}
+		line = strings.Join(words, p.separators().glue())
+	} else if line, err = p.renderFlat(vals); err != nil {
+		return
+	}
+	if _, mErr := p.MatchString(line); mErr != nil {
+		err = fmt.Errorf("input: rendered line does not read back: %w", mErr)
+	}
+	return
+}
+
+// renderFlat writes vals into a format without groups, keeping its
+// separators.
+func (p *Pattern) renderFlat(vals map[string]any) (line string, err error) {
+	sep := p.separators()
+	specs := make([]*varSpec, 0, p.nvars)
+	for _, e := range p.elems {
+		if e.spec != nil {
+			specs = append(specs, e.spec)
+		}
+	}
+	var b strings.Builder
+	format := p.format
+	for x := 0; len(format) > 0; x++ {
+		start := strings.Index(format, "${")
+		if start < 0 || x >= len(specs) {
+			b.WriteString(format)
+			break
+		}
+		end := start + placeholderEnd(format[start:])
+		b.WriteString(format[:start])
+		format = format[end:]
+		spec := specs[x]
+		val, ok := vals[spec.name]
+		if ok && val == nil {
+			ok = false
+		}
+		if !ok && spec.optional && laterOptionalSet(specs[x+1:], vals) && spec.hasDef {
+			val, ok = spec.def, true
+		}
+		if !ok {
+			if !spec.optional || laterOptionalSet(specs[x+1:], vals) {
+				err = fmt.Errorf("input: no value for %s", spec.name)
+				return
+			}
+			trimmed := strings.TrimRightFunc(b.String(), func(r rune) bool {
+				return unicode.IsSpace(r) || strings.ContainsRune(sep.Runes, r)
+			})
+			b.Reset()
+			b.WriteString(trimmed)
+			continue
+		}
+		var str string
+		if str, err = spec.render(val, sep); err != nil {
+			return
+		}
+		b.WriteString(str)
+	}
+	line = b.String()
+	return
+}
+
+// renderNode appends the words of n for vals to words. x is the turn of
+// the innermost repeated group, or -1 outside of one.
+func (p *Pattern) renderNode(n *node, vals map[string]any, x int, words []string) (out []string, err error) {
+	out = words
+	switch n.kind {
+	case wordNode:
+		var b strings.Builder
+		for _, e := range n.word {
+			if e.spec == nil {
+				b.WriteString(e.literal)
+				continue
+			}
+			val, ok := renderValue(e.spec, vals, x)
+			if !ok {
+				err = fmt.Errorf("input: no value for %s", e.spec.name)
+				return
+			}
+			var str string
+			if str, err = renderKind(e.spec.kind, val, p.separators()); err != nil {
+				err = fmt.Errorf("input: %s: %w", e.spec.name, err)
+				return
+			}
+			b.WriteString(str)
+		}
+		out = append(out, b.String())
+	case seqNode:
+		for _, sub := range n.subs {
+			if out, err = p.renderNode(sub, vals, x, out); err != nil {
+				return
+			}
+		}
+	case altNode:
+		for _, sub := range n.subs {
+			if sub.renders(vals, x, true) {
+				out, err = p.renderNode(sub, vals, x, out)
+				return
+			}
+		}
+		err = fmt.Errorf("input: no values for any of %s", n)
+	case optNode:
+		if n.subs[0].renders(vals, x, false) {
+			out, err = p.renderNode(n.subs[0], vals, x, out)
+		}
+	case repNode:
+		turns := n.min
+		n.walk(func(s *varSpec) {
+			if v := reflect.ValueOf(vals[s.name]); s.repeated && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() > turns {
+				turns = v.Len()
+			}
+		})
+		for t := 0; t < turns; t++ {
+			if out, err = p.renderNode(n.subs[0], vals, t, out); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// renders reports whether n can be written for vals: when all is set,
+// every placeholder of n has a value or a default; otherwise one of them
+// was given a value, and a group without placeholders is left out.
+func (n *node) renders(vals map[string]any, x int, all bool) (ok bool) {
+	ok = all
+	n.walk(func(s *varSpec) {
+		has := false
+		if all {
+			_, has = renderValue(s, vals, x)
+		} else {
+			_, has = givenValue(s, vals, x)
+		}
+		if has != all {
+			ok = has
+		}
+	})
+	return
+}
+
+// walk calls fn for the placeholders of n in order.
+func (n *node) walk(fn func(s *varSpec)) {
+	for _, e := range n.word {
+		if e.spec != nil {
+			fn(e.spec)
+		}
+	}
+	for _, sub := range n.subs {
+		sub.walk(fn)
+	}
+}
+
+// renderValue is the value of s in vals, falling back to its default.
+func renderValue(s *varSpec, vals map[string]any, x int) (val any, ok bool) {
+	if val, ok = givenValue(s, vals, x); !ok {
+		val, ok = s.def, s.hasDef && !s.repeated
+	}
+	return
+}
+
+// givenValue is the value of s in vals, or item x of it for a placeholder
+// that repeats.
+func givenValue(s *varSpec, vals map[string]any, x int) (val any, ok bool) {
+	if val = vals[s.name]; val == nil {
+		return
+	}
+	if ok = true; s.repeated {
+		v := reflect.ValueOf(val)
+		if ok = (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && x >= 0 && x < v.Len(); ok {
+			val = v.Index(x).Interface()
+		}
+	}
+	return
+}
+
+// laterOptionalSet reports whether an optional, non variadic placeholder
+// among specs has a value, which would shift onto an earlier omitted one.
+func laterOptionalSet(specs []*varSpec, vals map[string]any) (ok bool) {
+	for _, s := range specs {
+		if s.optional && !s.variadic && vals[s.name] != nil {
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+// placeholderEnd returns the offset just past the `}` closing the
+// placeholder at the start of s.
+func placeholderEnd(s string) (end int) {
+	lvl := 0
+	for end = 1; end < len(s); end++ {
+		switch s[end] {
+		case '{':
+			lvl++
+		case '}':
+			if lvl--; lvl == 0 {
+				end++
+				return
+			}
+		}
+	}
+	return
+}
+
+func (s *varSpec) render(val any, sep Separators) (str string, err error) {
+	if !s.variadic {
+		if str, err = renderKind(s.kind, val, sep); err != nil {
+			err = fmt.Errorf("input: %s: %w", s.name, err)
+		}
+		return
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		rv = reflect.ValueOf([]any{val})
+	}
+	words := make([]string, rv.Len())
+	for x := range words {
+		if words[x], err = renderKind(s.kind, rv.Index(x).Interface(), sep); err != nil {
+			err = fmt.Errorf("input: %s[%d]: %w", s.name, x, err)
+			return
+		}
+	}
+	str = strings.Join(words, sep.glue())
+	return
+}
+
+// renderKind writes val as a single token that reads back as a value of
+// Kind k.
+func renderKind(k Kind, val any, sep Separators) (str string, err error) {
+	rv := reflect.ValueOf(val)
+	e, custom := kindDef(k)
+	switch {
+	case custom:
+		str = e.format(val)
+	case k == RGBHex:
+		str, err = renderHex(val)
+		return
+	case k == Bytes || k == Duration:
+		if str, err = renderUnit(k, val); err != nil {
+			return
+		}
+	case rv.Kind() == reflect.String && (k == String || k == Any):
+		var ok bool
+		if str, ok = renderString(rv.String(), sep); !ok {
+			err = fmt.Errorf("%q cannot be written as a single token", val)
+		}
+		return
+	case k == Float && rv.IsValid() && isNumber(rv.Type()):
+		str = renderFloat(rv.Convert(floatType).Float())
+	case (k == Int || k == Uint) && rv.IsValid() && isInteger(rv.Type()):
+		str = fmt.Sprintf(KindFmtSymbol(k), val)
+	case k == Bool && rv.Kind() == reflect.Bool:
+		str = fmt.Sprintf(KindFmtSymbol(k), val)
+	case k == Any, k == Array, k == Map, k == Byte:
+		if str, err = renderLiteral(val); err != nil {
+			return
+		}
+	default:
+		err = fmt.Errorf("cannot render %T as %s", val, KindString(k))
+		return
+	}
+	if words := sep.split(str); len(words) != 1 || words[0] != str {
+		err = fmt.Errorf("%q does not read back as a single token", str)
+	}
+	return
+}
+
+func renderFloat(f float64) (str string) {
+	str = strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(str, ".eEnN") {
+		str += ".0"
+	}
+	return
+}
+
+// renderString writes s bare when it reads back as itself, and quoted
+// otherwise. ok is false when no quoting reads back as s.
+func renderString(s string, sep Separators) (str string, ok bool) {
+	for _, str = range []string{s, "'" + s + "'", strconv.Quote(s)} {
+		if toks, er := sep.Tokenize(str); er == nil && len(toks) == 1 && toks[0].Raw == str && evalArg(str) == s {
+			ok = true
+			return
+		}
+	}
+	return
+}
+
+// renderLiteral writes val as an expr literal: numbers, strings, bools,
+// nil, arrays and maps with string keys.
+func renderLiteral(val any) (str string, err error) {
+	rv := reflect.ValueOf(val)
+	if !rv.IsValid() {
+		str = "nil"
+		return
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		str = strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		str = strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		str = strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		str = renderFloat(rv.Float())
+	case reflect.String:
+		str = strconv.Quote(rv.String())
+	case reflect.Slice, reflect.Array:
+		items := make([]string, rv.Len())
+		for x := range items {
+			if items[x], err = renderLiteral(rv.Index(x).Interface()); err != nil {
+				return
+			}
+		}
+		str = "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			err = fmt.Errorf("cannot render map keys of type %s", rv.Type().Key())
+			return
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for x, k := range keys {
+			var v string
+			if v, err = renderLiteral(rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface()); err != nil {
+				return
+			}
+			items[x] = strconv.Quote(k) + ": " + v
+		}
+		str = "{" + strings.Join(items, ", ") + "}"
+	default:
+		err = fmt.Errorf("cannot render %T", val)
+	}
+	return
+}
+
+// renderHex writes a color as #rrggbb, or #rrggbbaa when it is not opaque.
+// val is a Color, a string ParseColor reads, or the red, green and blue
+// channels as a slice or array of numbers.
+func renderHex(val any) (str string, err error) {
+	switch c := val.(type) {
+	case Color:
+		str = c.Hex()
+		return
+	case string:
+		var pc Color
+		if pc, err = ParseColor(c); err == nil {
+			str = pc.Hex()
+		}
+		return
+	}
+	rv := reflect.ValueOf(val)
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() != 3 {
+		err = fmt.Errorf("cannot render %T as %s", val, KindString(RGBHex))
+		return
+	}
+	rgb := make([]any, 3)
+	for x := range rgb {
+		var n uint64
+		if n, err = toUint64(reflect.ValueOf(rv.Index(x).Interface())); err != nil || n > 0xff {
+			err = fmt.Errorf("channel %d of %v is not a byte", x, val)
+			return
+		}
+		rgb[x] = n
+	}
+	str = "#" + fmt.Sprintf(KindFmtSymbol(RGBHex), rgb...)
+	return
+}
+
+// renderUnit writes a size or a duration in human form. val is a ByteSize
+// or time.Duration, a string that parses as one, or a number of bytes or
+// seconds.
+func renderUnit(k Kind, val any) (str string, err error) {
+	rv := reflect.ValueOf(val)
+	switch v := val.(type) {
+	case ByteSize:
+		str = v.String()
+		return
+	case time.Duration:
+		str = formatDuration(v)
+		return
+	case string:
+		if k == Bytes {
+			var b ByteSize
+			if b, err = ParseBytes(v); err == nil {
+				str = b.String()
+			}
+		} else {
+			var d time.Duration
+			if d, err = ParseDuration(v); err == nil {
+				str = formatDuration(d)
+			}
+		}
+		return
+	}
+	switch {
+	case k == Bytes && rv.IsValid() && isInteger(rv.Type()):
+		var n uint64
+		if n, err = toUint64(rv); err == nil {
+			str = ByteSize(n).String()
+		}
+	case k == Duration && rv.IsValid() && isNumber(rv.Type()):
+		str = formatDuration(time.Duration(rv.Convert(floatType).Float() * float64(time.Second)))
+	default:
+		err = fmt.Errorf("cannot render %T as %s", val, KindString(k))
+	}
+	return
+}
+
+// renderValues turns the argument of Render into values keyed by
+// placeholder name.
+func renderValues(values any) (vals map[string]any, err error) {
+	if m, ok := values.(map[string]any); ok {
+		vals = m
+		return
+	}
+	rv := reflect.ValueOf(values)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		err = errors.New("input: Render needs a map[string]any or a struct")
+		return
+	}
+	vals = structValues(rv)
+	return
+}
+
+// structValues is the reverse of bindStruct: it collects the exported
+// fields of a struct keyed by their input tag or name.
+func structValues(rv reflect.Value) (vals map[string]any) {
+	typ := rv.Type()
+	vals = make(map[string]any, typ.NumField())
+	for x := 0; x < typ.NumField(); x++ {
+		f := typ.Field(x)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("input"); ok {
+			if name = strings.Split(tag, ",")[0]; name == "-" {
+				continue
+			}
+		}
+		fv := rv.Field(x)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		switch {
+		case fv.Type() == durationType:
+			vals[name] = fv.Interface().(fmt.Stringer).String()
+		case fv.Type() == colorType:
+			vals[name] = fv.Interface()
+		case fv.Kind() == reflect.Struct:
+			vals[name] = structValues(fv)
+		default:
+			vals[name] = fv.Interface()
+		}
+	}
+	return
+}
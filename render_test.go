@@ -0,0 +1,70 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		format string
+		values any
+		want   string
+	}{
+		{"${command:String}: ${args:Int}, name:${n:String}", map[string]any{"command": "run", "args": 5, "n": "bob smith"}, "run: 5, name:'bob smith'"},
+		{"say ${msg}", map[string]any{"msg": "hello, world"}, "say 'hello, world'"},
+		{"say ${msg:String}", map[string]any{"msg": "42"}, "say '42'"},
+		{"pi ${x:Float}", map[string]any{"x": 0.1}, "pi 0.1"},
+		{"light ${c:RGBHex}", map[string]any{"c": Color{R: 255, A: 255}}, "light #ff0000"},
+		{"wait ${d:Duration}", map[string]any{"d": 90 * time.Second}, "wait 1m30s"},
+		{"ls ${n:Int=10} ${path?:String}", map[string]any{"path": "/tmp"}, "ls 10 /tmp"},
+		{"ls ${n:Int=10} ${path?:String}", map[string]any{}, "ls"},
+		{"tag ${labels...:String}", map[string]any{"labels": []string{"a", "b c"}}, "tag a 'b c'"},
+		{"(add|remove) ${item} [to ${list}]", map[string]any{"item": "x"}, "add x"},
+		{"(add|remove) ${item} [to ${list}]", map[string]any{"item": "x", "list": "todo"}, "add x to todo"},
+		{"set (${k:String}=${v:Int})+", map[string]any{"k": []string{"a", "b"}, "v": []int{1, 2}}, "set a=1 b=2"},
+		{"go ${n:Int}", struct {
+			N int `input:"n"`
+		}{3}, "go 3"},
+	}
+	for _, tt := range tests {
+		p := MustCompile(tt.format)
+		line, err := p.Render(tt.values)
+		if err != nil || line != tt.want {
+			t.Errorf("%s: got %q, %v, want %q", tt.format, line, err, tt.want)
+			continue
+		}
+		vals, ok := tt.values.(map[string]any)
+		if !ok {
+			continue
+		}
+		in, err := p.MatchString(line)
+		if err != nil {
+			t.Errorf("%s: %q does not read back: %v", tt.format, line, err)
+			continue
+		}
+		for name, want := range vals {
+			if got := in.Get(name).Value; !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s reads back as %#v, want %#v", tt.format, name, got, want)
+			}
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		values any
+		err    string
+	}{
+		{"go ${dir}", map[string]any{}, "input: no value for dir"},
+		{"go ${n:Int}", map[string]any{"n": "x"}, "input: n: cannot render string as Int"},
+		{"go ${n:Int}", 5, "input: Render needs a map[string]any or a struct"},
+	}
+	for _, tt := range tests {
+		if _, err := MustCompile(tt.format).Render(tt.values); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got error %v, want %s", tt.format, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultMinScore is the MinScore of a Router returned by NewRouter.
+const DefaultMinScore = 0.5
+
+// ErrNoMatch is returned by a Router without a fallback handler when no
+// format reaches its MinScore.
+var ErrNoMatch = errors.New("input: no format matches")
+
+// HandlerFunc handles an Input dispatched by a Router. The Input may still
+// carry a *MatchError in Err when a literal of the winning format was only
+// a fuzzy hit.
+type HandlerFunc func(in *Input) error
+
+// Route is a format registered on a Router together with its handler.
+type Route struct {
+	pattern *Pattern
+	handler HandlerFunc
+}
+
+func (rt *Route) Pattern() (p *Pattern) {
+	p = rt.pattern
+	return
+}
+
+// AmbiguousError is returned when two or more formats share the top score.
+type AmbiguousError struct {
+	Line    string
+	Score   float64
+	Formats []string
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("input: %q is ambiguous between %s (score %.2f)", e.Line, strings.Join(e.Formats, ", "), e.Score)
+}
+
+// Router scores a line against every registered format and dispatches it to
+// the handler of the best one. It is safe for concurrent use.
+type Router struct {
+	// MinScore is the lowest score a format needs to be dispatched.
+	MinScore float64
+
+	mu        sync.RWMutex
+	routes    []*Route
+	fallback  HandlerFunc
+	providers map[string]ProviderFunc
+}
+
+func NewRouter() (r *Router) {
+	r = &Router{MinScore: DefaultMinScore}
+	return
+}
+
+// Handle compiles format with opts and registers h to run when it wins.
+func (r *Router) Handle(format string, h HandlerFunc, opts ...Option) (rt *Route, err error) {
+	p, err := Compile(format, opts...)
+	if err != nil {
+		return
+	}
+	rt = &Route{pattern: p, handler: h}
+	r.mu.Lock()
+	r.routes = append(r.routes, rt)
+	r.mu.Unlock()
+	return
+}
+
+// Fallback sets the handler run when no format reaches MinScore. It gets the
+// best scoring Input, or an Input holding only the line when no format is
+// registered.
+func (r *Router) Fallback(h HandlerFunc) {
+	r.mu.Lock()
+	r.fallback = h
+	r.mu.Unlock()
+}
+
+func (r *Router) Routes() (routes []*Route) {
+	r.mu.RLock()
+	routes = append(routes, r.routes...)
+	r.mu.RUnlock()
+	return
+}
+
+// Match scores line against every format and returns the winner. Only a
+// format that matched line, or whose sole errors are fuzzy hits on its
+// literals, can win. rt is nil when no such format reaches MinScore; in
+// that case in is the best candidate, if any. err is an *AmbiguousError
+// when the top scores tie.
+func (r *Router) Match(line string) (in *Input, rt *Route, err error) {
+	var best *Input
+	var tied []*Route
+	for _, cand := range r.Routes() {
+		ci, _ := cand.pattern.MatchString(line)
+		if best == nil || ci.score > best.score {
+			best = ci
+		}
+		if !dispatchable(ci) {
+			continue
+		}
+		switch {
+		case in == nil || ci.score > in.score:
+			in, rt, tied = ci, cand, tied[:0]
+		case ci.score == in.score:
+			tied = append(tied, cand)
+		}
+	}
+	if in == nil || in.score < r.MinScore {
+		in, rt = best, nil
+		return
+	}
+	if len(tied) > 0 {
+		amb := &AmbiguousError{Line: line, Score: in.score, Formats: []string{rt.pattern.format}}
+		for _, t := range tied {
+			amb.Formats = append(amb.Formats, t.pattern.format)
+		}
+		in, rt, err = nil, nil, amb
+	}
+	return
+}
+
+// dispatchable reports whether in may be handed to the handler of its
+// format: it matched, or its only errors are literals typed close enough
+// for WithFuzzy to suggest them.
+func dispatchable(in *Input) (ok bool) {
+	me, isMatch := in.err.(*MatchError)
+	if ok = in.err == nil; ok || !isMatch {
+		return
+	}
+	for _, te := range me.Errors {
+		if te.Reason != LiteralMismatch || te.Suggest == "" {
+			return
+		}
+	}
+	ok = true
+	return
+}
+
+// Dispatch runs the handler of the format that best matches line.
+func (r *Router) Dispatch(line string) (err error) {
+	in, rt, err := r.Match(line)
+	if err != nil {
+		return
+	}
+	if rt != nil {
+		err = rt.handler(in)
+		return
+	}
+	r.mu.RLock()
+	fb := r.fallback
+	r.mu.RUnlock()
+	if fb == nil {
+		err = ErrNoMatch
+		if in != nil && in.err != nil {
+			err = fmt.Errorf("%w: %v", ErrNoMatch, in.err)
+		}
+		return
+	}
+	if in == nil {
+		in = &Input{line: line}
+	}
+	err = fb(in)
+	return
+}
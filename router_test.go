@@ -0,0 +1,77 @@
+package input
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRouterDispatch(t *testing.T) {
+	var got string
+	r := NewRouter()
+	for _, format := range []string{"deploy ${env}", "deploy ${env} ${port:Int}", "destroy ${env}"} {
+		format := format
+		if _, err := r.Handle(format, func(in *Input) error { got = format; return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		line, want string
+		err        error
+	}{
+		{"deploy prod", "deploy ${env}", nil},
+		{"deploy prod 8080", "deploy ${env} ${port:Int}", nil},
+		{"destroy prod", "destroy ${env}", nil},
+		{"launch prod", "", ErrNoMatch},
+		{"", "", ErrNoMatch},
+	}
+	for _, tt := range tests {
+		got = ""
+		err := r.Dispatch(tt.line)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("%q: ran %q with %v, want %q with %v", tt.line, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestRouterSkipsWrongKeyword(t *testing.T) {
+	ran := false
+	r := NewRouter()
+	r.Handle("destroy ${env}", func(in *Input) error { ran = true; return nil })
+	if err := r.Dispatch("deploy prod"); !errors.Is(err, ErrNoMatch) || ran {
+		t.Errorf("Dispatch ran the handler: %v", err)
+	}
+	in, rt, err := r.Match("deploy prod")
+	if rt != nil || err != nil {
+		t.Errorf("Match returned route %v, %v", rt, err)
+	}
+	if in == nil || in.Err() == nil {
+		t.Errorf("Match should return the failed candidate")
+	}
+	var fell *Input
+	r.Fallback(func(in *Input) error { fell = in; return nil })
+	if err := r.Dispatch("deploy prod"); err != nil || ran || fell == nil {
+		t.Errorf("Dispatch did not fall back: %v", err)
+	}
+}
+
+func TestRouterFuzzyHitIsDispatched(t *testing.T) {
+	var got *Input
+	r := NewRouter()
+	r.Handle("deploy ${env}", func(in *Input) error { got = in; return nil }, WithFuzzy(2))
+	if err := r.Dispatch("depoly prod"); err != nil || got == nil {
+		t.Fatalf("fuzzy hit not dispatched: %v", err)
+	}
+	if got.Get("env").Value != "prod" || len(got.Suggestions()) != 1 {
+		t.Errorf("got %v with suggestions %v", got.All(), got.Suggestions())
+	}
+}
+
+func TestRouterAmbiguous(t *testing.T) {
+	r := NewRouter()
+	r.Handle("say ${a}", func(in *Input) error { return nil })
+	r.Handle("say ${b}", func(in *Input) error { return nil })
+	var amb *AmbiguousError
+	if err := r.Dispatch("say hi"); !errors.As(err, &amb) || len(amb.Formats) != 2 {
+		t.Errorf("got %v, want an AmbiguousError", err)
+	}
+}
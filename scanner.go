@@ -0,0 +1,108 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Scanner reads records one at a time from a reader and matches each one
+// against a Pattern, so large files and scripts are never held in memory.
+// Records are lines by default; blank records are skipped. Its methods
+// follow bufio.Scanner.
+type Scanner struct {
+	sc     *bufio.Scanner
+	p      *Pattern
+	lineNo int
+	in     *Input
+	err    error
+}
+
+func NewScanner(r io.Reader, p *Pattern) (s *Scanner) {
+	s = &Scanner{sc: bufio.NewScanner(r), p: p}
+	return
+}
+
+// Delimiter sets the string that ends a record instead of a newline. It
+// must be called before the first Scan. An empty delimiter is an error:
+// Scan then reads nothing and Err reports it.
+func (s *Scanner) Delimiter(delim string) {
+	if delim == "" {
+		s.err = errors.New("input: empty record delimiter")
+		return
+	}
+	if delim == "\n" {
+		s.sc.Split(bufio.ScanLines)
+		return
+	}
+	sep := []byte(delim)
+	s.sc.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return
+		}
+		if n := bytes.Index(data, sep); n >= 0 {
+			advance, token = n+len(sep), data[:n]
+			return
+		}
+		if atEOF {
+			advance, token = len(data), data
+		}
+		return
+	})
+}
+
+// Buffer sets the initial buffer and the largest record the Scanner
+// accepts, as bufio.Scanner.Buffer does. It must be called before the first
+// Scan.
+func (s *Scanner) Buffer(buf []byte, max int) {
+	s.sc.Buffer(buf, max)
+}
+
+// Scan advances to the next non-blank record and matches it. It returns
+// false at the end of the input or on a read error. A record that does not
+// match is not a read error: Scan still returns true and Input().Err()
+// holds the *MatchError, with LineNo set to the record's number.
+func (s *Scanner) Scan() (ok bool) {
+	s.in = nil
+	if s.err != nil {
+		return
+	}
+	for s.sc.Scan() {
+		s.lineNo++
+		line := s.sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		s.in = &Input{}
+		if err := s.p.read(s.in, line, nil); err != nil {
+			err.(*MatchError).LineNo = s.lineNo
+		}
+		ok = true
+		return
+	}
+	return
+}
+
+// Input is the match result of the record read by the last Scan.
+func (s *Scanner) Input() (i *Input) {
+	i = s.in
+	return
+}
+
+// LineNo is the 1-based number of the record read by the last Scan,
+// counting blank ones.
+func (s *Scanner) LineNo() (n int) {
+	n = s.lineNo
+	return
+}
+
+// Err is the first non-EOF read error met by Scan, or the error of a bad
+// Delimiter.
+func (s *Scanner) Err() (err error) {
+	if err = s.err; err == nil {
+		err = s.sc.Err()
+	}
+	return
+}
@@ -0,0 +1,56 @@
+package input
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		delim, text string
+		want        []string
+		bad         []int
+	}{
+		{"", "set a 1\n\nset b x\nset c 3\n", []string{"a", "b", "c"}, []int{3}},
+		{";", "set a 1;set b 2;;set c 3", []string{"a", "b", "c"}, nil},
+		{"\n", "set a 1\r\nset b 2", []string{"a", "b"}, nil},
+	}
+	p := MustCompile("set ${k} ${v:Int}")
+	for _, tt := range tests {
+		sc := NewScanner(strings.NewReader(tt.text), p)
+		if tt.delim != "" {
+			sc.Delimiter(tt.delim)
+		}
+		var got []string
+		var bad []int
+		for sc.Scan() {
+			in := sc.Input()
+			got = append(got, in.Get("k").Value.(string))
+			var me *MatchError
+			if errors.As(in.Err(), &me) {
+				if me.LineNo != sc.LineNo() {
+					t.Errorf("MatchError.LineNo is %d, want %d", me.LineNo, sc.LineNo())
+				}
+				bad = append(bad, sc.LineNo())
+			}
+		}
+		if err := sc.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") || len(bad) != len(tt.bad) || len(bad) > 0 && bad[0] != tt.bad[0] {
+			t.Errorf("%q: got %v with bad lines %v, want %v and %v", tt.text, got, bad, tt.want, tt.bad)
+		}
+	}
+}
+
+func TestScannerEmptyDelimiter(t *testing.T) {
+	sc := NewScanner(strings.NewReader("a;b"), MustCompile("${x}"))
+	sc.Delimiter("")
+	if sc.Scan() {
+		t.Error("Scan read a record with an empty delimiter")
+	}
+	if sc.Err() == nil {
+		t.Error("Err is nil after an empty delimiter")
+	}
+}
@@ -0,0 +1,129 @@
+package input
+
+// Weights are the points a slot of a match can earn. A slot is a literal
+// word or placeholder of the format together with the token it took, or a
+// token left over past the end of the format. The score of a match is
+//
+//	sum of the points earned / sum of the points each slot could earn
+//
+// so it lies between 0 and 1, and is 1 when nothing could be earned.
+//
+//   - A literal typed exactly earns Literal; one that is only a fuzzy hit
+//     earns a share of it that shrinks with the edit distance.
+//   - A placeholder token of its Kind earns Kind, or Coercion instead when
+//     it had to be converted to the Kind. When the placeholder has a
+//     constraint, passing it earns Constraint on top.
+//   - A placeholder left out for its default earns Default out of Default,
+//     so a larger Default only dilutes the other slots.
+//   - A missing literal or placeholder earns nothing out of its full
+//     points, and a leftover token earns nothing out of the larger of
+//     Literal and Kind.
+//
+// Each token of a variadic placeholder is a slot of its own.
+type Weights struct {
+	Literal    float64
+	Kind       float64
+	Coercion   float64
+	Constraint float64
+	Default    float64
+}
+
+// DefaultWeights are used by Patterns not compiled WithWeights.
+var DefaultWeights = Weights{Literal: 1, Kind: 1, Coercion: 0.5, Constraint: 1}
+
+// WithWeights scores matches of the Pattern with w instead of
+// DefaultWeights.
+func WithWeights(w Weights) Option {
+	return func(p *Pattern) {
+		p.weights = &w
+	}
+}
+
+func (p *Pattern) scoreWeights() (w Weights) {
+	if w = DefaultWeights; p.weights != nil {
+		w = *p.weights
+	}
+	return
+}
+
+// SlotScore is the part of a score earned by one slot of a match.
+type SlotScore struct {
+	// Pos is the position of the token in the line, or -1 when the slot
+	// took no token of it.
+	Pos   int
+	Token string
+	// Literal is the literal word of the slot, Name its placeholder name;
+	// both are empty for a leftover token.
+	Literal string
+	Name    string
+
+	LiteralHit     bool
+	KindHit        bool
+	Coerced        bool
+	ConstraintPass bool
+	DefaultUsed    bool
+
+	Points float64
+	Max    float64
+}
+
+// ScoreReport breaks the score of a match down by slot, in the order the
+// slots were matched.
+type ScoreReport struct {
+	Weights Weights
+	Slots   []SlotScore
+	Points  float64
+	Max     float64
+	Score   float64
+}
+
+func (r *ScoreReport) add(s SlotScore) {
+	r.Slots = append(r.Slots, s)
+	r.Points += s.Points
+	r.Max += s.Max
+	r.rescore()
+}
+
+// revokeName takes back the points of the slots of the placeholder called
+// name, when a variadic placeholder fails as a whole.
+func (r *ScoreReport) revokeName(name string) {
+	for x := range r.Slots {
+		if r.Slots[x].Name == name {
+			r.Points -= r.Slots[x].Points
+			r.Slots[x].Points = 0
+			r.Slots[x].ConstraintPass = false
+		}
+	}
+	r.rescore()
+}
+
+func (r *ScoreReport) rescore() {
+	r.Score = 1
+	if r.Max > 0 {
+		r.Score = r.Points / r.Max
+	}
+}
+
+// slotMax is what a placeholder token of s can earn.
+func (w Weights) slotMax(s *varSpec) (max float64) {
+	if max = w.Kind; s.constraint != nil {
+		max += w.Constraint
+	}
+	return
+}
+
+// hit scores a token that parsed for s. checked is the result of its
+// constraint, when s has one.
+func (w Weights) hit(s *varSpec, pos int, raw string, coerced bool, checked error) (ss SlotScore) {
+	ss = SlotScore{Pos: pos, Token: raw, Name: s.name, KindHit: true, Coerced: coerced, Max: w.slotMax(s)}
+	if coerced {
+		ss.Points = w.Coercion
+	} else {
+		ss.Points = w.Kind
+	}
+	if s.constraint != nil && checked == nil {
+		ss.ConstraintPass = true
+		ss.Points += w.Constraint
+	}
+	return
+}
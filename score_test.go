@@ -0,0 +1,63 @@
+package input
+
+import (
+	"math"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		format, line string
+		opts         []Option
+		score        float64
+	}{
+		{"deploy", "deploy", nil, 1},
+		{"deploy ${env} ${port:Int(1..9)=8}", "deploy prod 5", nil, 1},
+		{"deploy ${env} ${port:Int(1..9)=8}", "deploy prod", nil, 1},
+		{"deploy ${env} ${port:Int(1..9)=8}", "deploy prod 50", nil, 3.0 / 4},
+		{"deploy ${env} ${port:Int(1..9)=8}", "deploy prod '5'", []Option{WithCoercion(Lenient)}, 3.5 / 4},
+		{"deploy ${env}", "deploy prod extra", nil, 2.0 / 3},
+		{"deploy ${env}", "deploy", nil, 1.0 / 2},
+		{"deploy ${env}", "launch prod", nil, 1.0 / 2},
+		{"deploy ${env} ${port:Int=8}", "deploy prod", []Option{WithWeights(Weights{Literal: 2, Kind: 1, Default: 1})}, 1},
+		{"deploy ${env} ${port:Int=8}", "deploy", []Option{WithWeights(Weights{Literal: 2, Kind: 1, Default: 1})}, 3.0 / 4},
+	}
+	for _, tt := range tests {
+		in, _ := MustCompile(tt.format, tt.opts...).MatchString(tt.line)
+		_, matches := in.Matches()
+		r := in.Report()
+		if math.Abs(in.Score()-tt.score) > 1e-9 || matches != in.Score() || r.Score != in.Score() {
+			t.Errorf("%s %q: got score %v, Matches %v and report %v, want %v", tt.format, tt.line, in.Score(), matches, r.Score, tt.score)
+		}
+		var points, max float64
+		for _, s := range r.Slots {
+			points, max = points+s.Points, max+s.Max
+		}
+		if math.Abs(points-r.Points) > 1e-9 || math.Abs(max-r.Max) > 1e-9 {
+			t.Errorf("%s %q: slots add up to %v/%v, report has %v/%v", tt.format, tt.line, points, max, r.Points, r.Max)
+		}
+	}
+}
+
+func TestScoreReport(t *testing.T) {
+	in, _ := MustCompile("deploy ${env} ${port:Int(1..9)} ${n:Int}", WithCoercion(Lenient)).MatchString("deploy prod 50 '3'")
+	want := []SlotScore{
+		{Pos: 0, Token: "deploy", Literal: "deploy", LiteralHit: true, Points: 1, Max: 1},
+		{Pos: 1, Token: "prod", Name: "env", KindHit: true, Points: 1, Max: 1},
+		{Pos: 2, Token: "50", Name: "port", KindHit: true, Points: 1, Max: 2},
+		{Pos: 3, Token: "'3'", Name: "n", KindHit: true, Coerced: true, Points: 0.5, Max: 1},
+	}
+	got := in.Report().Slots
+	if len(got) != len(want) {
+		t.Fatalf("got slots %+v", got)
+	}
+	for x := range want {
+		if got[x] != want[x] {
+			t.Errorf("slot %d is %+v, want %+v", x, got[x], want[x])
+		}
+	}
+	in, _ = MustCompile("deploy ${env} ${port:Int=8}").MatchString("deploy prod")
+	if s := in.Report().Slots[2]; !s.DefaultUsed || s.Pos != -1 || s.Name != "port" {
+		t.Errorf("default slot is %+v", s)
+	}
+}
@@ -0,0 +1,231 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a token of a line and where it lies in it.
+type Token struct {
+	// Text is the token with its quotes taken off and its escapes read.
+	Text string
+	// Raw is the token as typed, and Start and End its byte offsets.
+	Raw        string
+	Start, End int
+	// Quoted is set when some of the token was quoted, and QuoteChar is
+	// the first quote used: ', " or `.
+	Quoted    bool
+	QuoteChar rune
+}
+
+// Separators say how a Pattern splits its format and its input into
+// tokens. Both are always split the same way.
+type Separators struct {
+	// Runes are the runes that end a token.
+	Runes string
+	// Collapse makes a run of separators one break. Otherwise each
+	// separator ends a token, so `a,,b` has an empty token in the middle.
+	Collapse bool
+	// KeepInBrackets keeps separators inside (), [] and {} in the token,
+	// so `f(a, b)` stays whole.
+	KeepInBrackets bool
+	// KeepInQuotes keeps separators inside quotes in the token, and reads
+	// the quotes and escapes of Tokenize. Otherwise quotes are plain text.
+	KeepInQuotes bool
+}
+
+// DefaultSeparators are used by Split, Tokenize and the Patterns not
+// compiled WithSeparators.
+var DefaultSeparators = Separators{Runes: " ,:", Collapse: true, KeepInBrackets: true, KeepInQuotes: true}
+
+// WithSeparators splits the format and the input of the Pattern with sep
+// instead of DefaultSeparators. To change a single field, start from a
+// copy of DefaultSeparators:
+//
+//	sep := input.DefaultSeparators
+//	sep.Runes = " " // keep http://host:8080 and 12:30 whole
+//	p, err := input.Compile("open ${url}", input.WithSeparators(sep))
+func WithSeparators(sep Separators) Option {
+	return func(p *Pattern) {
+		p.seps = &sep
+	}
+}
+
+func (p *Pattern) separators() (sep Separators) {
+	if sep = DefaultSeparators; p.seps != nil {
+		sep = *p.seps
+	}
+	return
+}
+
+// Tokenize splits line into tokens with DefaultSeparators, at spaces,
+// commas and colons, as Split does, and reads each one as a shell would:
+//
+//	"it's"        it's           quotes of the other kind are text
+//	'say \"hi\"'  say "hi"       \", \', \\ and \n are read in and out
+//	                             of quotes; other backslashes are kept
+//	a"b c"d       ab cd          quoted parts join the text around them
+//	`C:\tmp`      C:\tmp         backticks take everything as it is
+//	f(a, b)       f(a, b)        separators in brackets do not split
+//
+// A quote or bracket left open is an error; the tokens are still returned,
+// the last one running to the end of line.
+func Tokenize(line string) (toks []Token, err error) {
+	toks, err = DefaultSeparators.Tokenize(line)
+	return
+}
+
+// Tokenize is the package Tokenize with the separators of sep. Spaces
+// around a token are trimmed, which matters when space is not a separator.
+func (sep Separators) Tokenize(line string) (toks []Token, err error) {
+	toks, open := sep.tokens(line)
+	if open == 0 {
+		return
+	}
+	tail := toks[len(toks)-1].Raw
+	if open == '\'' || open == '"' || open == '`' {
+		err = fmt.Errorf("input: unterminated %c quote in %q", open, tail)
+	} else {
+		err = fmt.Errorf("input: unclosed %c in %q", open, tail)
+	}
+	return
+}
+
+// tokens is Tokenize, with open the quote or bracket left open at the end
+// of line, or 0.
+func (sep Separators) tokens(line string) (toks []Token, open rune) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	var quote rune
+	var brackets []rune
+	start := 0
+	emit := func(end int) {
+		s, e := start, end
+		for s < e {
+			r, size := utf8.DecodeRuneInString(line[s:e])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			s += size
+		}
+		for e > s {
+			r, size := utf8.DecodeLastRuneInString(line[s:e])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			e -= size
+		}
+		if s < e || !sep.Collapse {
+			toks = append(toks, sep.token(line, s, e))
+		}
+	}
+	for x := 0; x < len(line); {
+		c, size := utf8.DecodeRuneInString(line[x:])
+		switch {
+		case sep.KeepInQuotes && c == '\\' && quote != '`' && x+1 < len(line) && strings.IndexByte(`"'\n`, line[x+1]) >= 0:
+			size++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case sep.KeepInQuotes && (c == '\'' || c == '"' || c == '`'):
+			quote = c
+		case sep.KeepInBrackets && (c == '(' || c == '[' || c == '{'):
+			brackets = append(brackets, c)
+		case sep.KeepInBrackets && (c == ')' || c == ']' || c == '}'):
+			if len(brackets) > 0 {
+				brackets = brackets[:len(brackets)-1]
+			}
+		case len(brackets) == 0 && strings.ContainsRune(sep.Runes, c):
+			emit(x)
+			start = x + size
+		}
+		x += size
+	}
+	if open = quote; open == 0 && len(brackets) > 0 {
+		open = brackets[len(brackets)-1]
+	}
+	emit(len(line))
+	return
+}
+
+// split is Split with the separators of sep.
+func (sep Separators) split(value string) (res []string) {
+	toks, _ := sep.tokens(value)
+	for _, t := range toks {
+		res = append(res, t.Raw)
+	}
+	return
+}
+
+// spans splits value as split does and keeps where each token lies, so a
+// token can be traced back to the text around it. open is the quote or
+// bracket left open at the end of value, or 0.
+func (sep Separators) spans(value string) (spans []span, open rune) {
+	toks, open := sep.tokens(value)
+	for _, t := range toks {
+		spans = append(spans, span{t.Start, t.End})
+	}
+	return
+}
+
+// glue is what Render writes between two tokens: a space when it is a
+// separator, and the first separator otherwise.
+func (sep Separators) glue() (str string) {
+	if str = " "; !strings.ContainsRune(sep.Runes, ' ') {
+		if r, size := utf8.DecodeRuneInString(sep.Runes); size > 0 {
+			str = string(r)
+		}
+	}
+	return
+}
+
+// token reads the token of line from start to end.
+func (sep Separators) token(line string, start, end int) (tok Token) {
+	tok = Token{Raw: line[start:end], Start: start, End: end}
+	if !sep.KeepInQuotes {
+		tok.Text = tok.Raw
+		return
+	}
+	var text strings.Builder
+	var quote rune
+	for x := start; x < end; x++ {
+		c := rune(line[x])
+		switch {
+		case c == '\\' && quote != '`' && x+1 < end && strings.IndexByte(`"'\n`, line[x+1]) >= 0:
+			if x++; line[x] == 'n' {
+				text.WriteByte('\n')
+			} else {
+				text.WriteByte(line[x])
+			}
+			continue
+		case quote != 0:
+			if c == quote {
+				quote = 0
+				continue
+			}
+		case c == '\'' || c == '"' || c == '`':
+			if quote = c; !tok.Quoted {
+				tok.Quoted, tok.QuoteChar = true, c
+			}
+			continue
+		}
+		text.WriteByte(line[x])
+	}
+	tok.Text = text.String()
+	return
+}
+
+// unquote is the Text of raw read as a single token, or raw itself when it
+// does not read as one.
+func unquote(raw string) (text string) {
+	text = raw
+	whole := Separators{KeepInBrackets: true, KeepInQuotes: true}
+	if toks, err := whole.Tokenize(raw); err == nil && len(toks) == 1 && toks[0].Raw == raw {
+		text = toks[0].Text
+	}
+	return
+}
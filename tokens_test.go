@@ -0,0 +1,154 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+		ok   bool
+	}{
+		{`"it's"`, []string{"it's"}, true},
+		{`'say \"hi\"'`, []string{`say "hi"`}, true},
+		{`a"b c"d`, []string{"ab cd"}, true},
+		{"`C:\\tmp`", []string{`C:\tmp`}, true},
+		{"f(a, b) x", []string{"f(a, b)", "x"}, true},
+		{`a\nb`, []string{"a\nb"}, true},
+		{`a\qb`, []string{`a\qb`}, true},
+		{"one, two:three", []string{"one", "two", "three"}, true},
+		{"voilà Å", []string{"voilà", "Å"}, true},
+		{"  ", nil, true},
+		{`say "hello`, []string{"say", "hello"}, false},
+		{"say [oops", []string{"say", "[oops"}, false},
+	}
+	for _, tt := range tests {
+		toks, err := Tokenize(tt.line)
+		if (err == nil) != tt.ok {
+			t.Errorf("Tokenize(%q): got error %v", tt.line, err)
+		}
+		var got []string
+		for _, tok := range toks {
+			got = append(got, tok.Text)
+			if tok.Raw != tt.line[tok.Start:tok.End] {
+				t.Errorf("Tokenize(%q): Raw %q is not the span %d:%d", tt.line, tok.Raw, tok.Start, tok.End)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestTokenQuotes(t *testing.T) {
+	toks, err := Tokenize(`plain 'single' a"b"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		quoted bool
+		char   rune
+	}{{false, 0}, {true, '\''}, {true, '"'}}
+	for x, w := range want {
+		if toks[x].Quoted != w.quoted || toks[x].QuoteChar != w.char {
+			t.Errorf("token %d: Quoted %v QuoteChar %q, want %v %q", x, toks[x].Quoted, toks[x].QuoteChar, w.quoted, w.char)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	got := Split(`set "a b" [1, 2]`)
+	want := []string{"set", `"a b"`, "[1, 2]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Split = %q, want %q", got, want)
+	}
+}
+
+func TestSeparators(t *testing.T) {
+	tests := []struct {
+		sep  Separators
+		line string
+		want []string
+	}{
+		{Separators{Runes: ",", Collapse: true}, "voilà , Å,b", []string{"voilà", "Å", "b"}},
+		{Separators{Runes: ","}, "a,,b", []string{"a", "", "b"}},
+		{Separators{Runes: ",", Collapse: true}, "a,,b", []string{"a", "b"}},
+		{Separators{Runes: " ", Collapse: true}, "open http://host:8080", []string{"open", "http://host:8080"}},
+		{Separators{Runes: " ,", Collapse: true}, "f(a, b)", []string{"f(a", "b)"}},
+		{Separators{Runes: " ", Collapse: true}, `"a b"`, []string{`"a`, `b"`}},
+		{Separators{Runes: "|", KeepInQuotes: true}, `'a|b'|c`, []string{"a|b", "c"}},
+	}
+	for _, tt := range tests {
+		toks, _ := tt.sep.Tokenize(tt.line)
+		var got []string
+		for _, tok := range toks {
+			got = append(got, tok.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: Tokenize(%q) = %q, want %q", tt.sep, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestWithSeparators(t *testing.T) {
+	sep := DefaultSeparators
+	sep.Runes = " "
+	p := MustCompile("open ${url}", WithSeparators(sep))
+	in, err := p.MatchString("open http://host:8080")
+	if err != nil || in.Get("url").Value != "http://host:8080" {
+		t.Errorf("got %v, %v", in.All(), err)
+	}
+	csv := MustCompile("${a},${b?:Int=7},${c}", WithSeparators(Separators{Runes: ","}))
+	in, err = csv.MatchString("x,,z")
+	if err != nil || in.Get("b").Value != 7 || in.Get("c").Value != "z" {
+		t.Errorf("got %v, %v", in.All(), err)
+	}
+	if line, err := csv.Render(map[string]any{"a": "x", "b": 1, "c": "z"}); err != nil || line != "x,1,z" {
+		t.Errorf("Render gave %q, %v", line, err)
+	}
+}
+
+func TestEmptyFormatWords(t *testing.T) {
+	sep := Separators{Runes: ","}
+	tests := []struct {
+		format, err string
+	}{
+		{"a,,${b}", `input: empty word in "a,,${b}"`},
+		{"a,${b},", `input: empty word in "a,${b},"`},
+		{",a", `input: empty word in ",a"`},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.format, WithSeparators(sep)); err == nil || err.Error() != tt.err {
+			t.Errorf("%q: got error %v, want %s", tt.format, err, tt.err)
+		}
+	}
+	sep.Collapse = true
+	if _, err := Compile("a,,${b}", WithSeparators(sep)); err != nil {
+		t.Errorf("collapsing separators: %v", err)
+	}
+}
+
+func TestUnterminatedInput(t *testing.T) {
+	tests := []struct {
+		format, line, want string
+	}{
+		{"say ${w}", `say "hello`, `token 1: " is never closed in "\"hello"`},
+		{"say ${w}", "say [oops", `token 1: [ is never closed in "[oops"`},
+		{"say ${w:Int}", "say (1", `token 1: ( is never closed in "(1"`},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format).MatchString(tt.line)
+		me, ok := err.(*MatchError)
+		if !ok || me.Errors[0].Reason != Unterminated || me.Errors[0].Error() != tt.want {
+			t.Errorf("%q: got %v, want %s", tt.line, err, tt.want)
+		}
+		if in.Score() >= 1 {
+			t.Errorf("%q: scored %v", tt.line, in.Score())
+		}
+	}
+	if _, err := MustCompile("say ${w}").MatchString(`say "hello world"`); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,190 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ByteSize is the value of a Bytes placeholder: a number of bytes.
+type ByteSize uint64
+
+var byteUnits = map[string]float64{
+	"b": 1, "byte": 1, "bytes": 1,
+	"k": 1e3, "kb": 1e3, "m": 1e6, "mb": 1e6, "g": 1e9, "gb": 1e9,
+	"t": 1e12, "tb": 1e12, "p": 1e15, "pb": 1e15, "e": 1e18, "eb": 1e18,
+	"ki": 1 << 10, "kib": 1 << 10, "mi": 1 << 20, "mib": 1 << 20, "gi": 1 << 30, "gib": 1 << 30,
+	"ti": 1 << 40, "tib": 1 << 40, "pi": 1 << 50, "pib": 1 << 50, "ei": 1 << 60, "eib": 1 << 60,
+}
+
+// byteSuffixes are the units ByteSize.String writes, largest first.
+var byteSuffixes = []struct {
+	name string
+	size float64
+}{
+	{"EiB", 1 << 60}, {"EB", 1e18}, {"PiB", 1 << 50}, {"PB", 1e15}, {"TiB", 1 << 40}, {"TB", 1e12},
+	{"GiB", 1 << 30}, {"GB", 1e9}, {"MiB", 1 << 20}, {"MB", 1e6}, {"KiB", 1 << 10}, {"kB", 1e3},
+}
+
+// ParseBytes reads a size with an SI unit (k, kB, MB, ... powers of 1000)
+// or an IEC one (Ki, KiB, MiB, ... powers of 1024): 10MB, 1.5GiB, 512k,
+// '64 KiB'. Units are case insensitive and a number alone counts bytes.
+func ParseBytes(s string) (b ByteSize, err error) {
+	num, unit, err := splitUnit(s)
+	if err != nil {
+		err = fmt.Errorf("input: bad size %q: %w", s, err)
+		return
+	}
+	scale := 1.0
+	if unit != "" {
+		var ok bool
+		if scale, ok = byteUnits[strings.ToLower(unit)]; !ok {
+			err = fmt.Errorf("input: bad size %q: unknown unit %q", s, unit)
+			return
+		}
+	}
+	n := math.Round(num * scale)
+	if n < 0 || n >= math.MaxUint64 {
+		err = fmt.Errorf("input: size %q is out of range", s)
+		return
+	}
+	b = ByteSize(n)
+	return
+}
+
+// String writes b in the largest unit that keeps it exact to two decimals,
+// falling back to bytes: 10MB, 1.5GiB, 1234567B.
+func (b ByteSize) String() string {
+	for _, u := range byteSuffixes {
+		if float64(b) < u.size {
+			continue
+		}
+		str := strconv.FormatFloat(float64(b)/u.size, 'f', -1, 64)
+		if _, frac, _ := strings.Cut(str, "."); len(frac) > 2 {
+			continue
+		}
+		if back, err := ParseBytes(str + u.name); err == nil && back == b {
+			return str + u.name
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "us": time.Microsecond, "µs": time.Microsecond, "ms": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// ParseDuration reads a duration as time.ParseDuration does, with days (d)
+// and weeks (w) on top, unit words, and spaces between the parts: 1h30m,
+// 90s, 2d12h, '2 days', '1 hour 30 minutes'. A number alone counts seconds.
+func ParseDuration(s string) (d time.Duration, err error) {
+	rest := strings.TrimSpace(s)
+	neg := strings.HasPrefix(rest, "-")
+	if neg || strings.HasPrefix(rest, "+") {
+		rest = rest[1:]
+	}
+	var total float64
+	if rest == "" || rest[0] == '-' || rest[0] == '+' {
+		err = errors.New("missing number")
+	} else if num, unit, er := splitUnit(rest); er == nil && unit == "" {
+		total = num * float64(time.Second)
+	} else {
+		for rest != "" {
+			end := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+			if end <= 0 {
+				err = errors.New("missing number")
+				break
+			}
+			var num float64
+			if num, err = strconv.ParseFloat(rest[:end], 64); err != nil {
+				break
+			}
+			rest = strings.TrimLeftFunc(rest[end:], unicode.IsSpace)
+			if end = strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsLetter(r) }); end < 0 {
+				end = len(rest)
+			}
+			unit, ok := durationUnits[strings.ToLower(rest[:end])]
+			if !ok {
+				err = fmt.Errorf("unknown unit %q", rest[:end])
+				break
+			}
+			total += num * float64(unit)
+			rest = strings.TrimLeftFunc(rest[end:], unicode.IsSpace)
+		}
+	}
+	if err == nil && math.Abs(total) >= math.MaxInt64 {
+		err = errors.New("out of range")
+	}
+	if err != nil {
+		d, err = 0, fmt.Errorf("input: bad duration %q: %v", s, err)
+		return
+	}
+	if d = time.Duration(total); neg {
+		d = -d
+	}
+	return
+}
+
+// formatDuration writes d as time.Duration.String does, with whole days
+// split off and zero trailing units dropped: 1h30m, 2d, 1m30s, 1.5s.
+func formatDuration(d time.Duration) (str string) {
+	if d < 0 {
+		str, d = "-", -d
+	}
+	if days := d / (24 * time.Hour); days > 0 {
+		str += strconv.FormatInt(int64(days), 10) + "d"
+		if d %= 24 * time.Hour; d == 0 {
+			return
+		}
+	}
+	rest := d.String()
+	if strings.HasSuffix(rest, "m0s") {
+		rest = strings.TrimSuffix(rest, "0s")
+	}
+	if strings.HasSuffix(rest, "h0m") {
+		rest = strings.TrimSuffix(rest, "0m")
+	}
+	str += rest
+	return
+}
+
+// splitUnit splits a number from the unit written after it.
+func splitUnit(s string) (num float64, unit string, err error) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, unicode.IsLetter)
+	if end < 0 {
+		end = len(s)
+	}
+	if num, err = strconv.ParseFloat(strings.TrimSpace(s[:end]), 64); err != nil {
+		err = fmt.Errorf("%q is not a number", strings.TrimSpace(s[:end]))
+		return
+	}
+	unit = s[end:]
+	return
+}
+
+// takesUnit reports whether s is a Bytes or Duration placeholder, which
+// may take a number and the unit word after it as one token.
+func (s *varSpec) takesUnit() bool {
+	return s.kind == Bytes || s.kind == Duration
+}
+
+// isUnitWord reports whether word can be the unit of a number typed before
+// it, as in `2 days` or `64 KiB`.
+func isUnitWord(word string) (ok bool) {
+	word = strings.ToLower(word)
+	_, ok = durationUnits[word]
+	if !ok {
+		_, ok = byteUnits[word]
+	}
+	return
+}
@@ -0,0 +1,113 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ByteSize
+		ok   bool
+	}{
+		{"10MB", 10e6, true},
+		{"1.5GiB", 3 << 29, true},
+		{"512k", 512e3, true},
+		{"64 KiB", 64 << 10, true},
+		{"100", 100, true},
+		{"2 bytes", 2, true},
+		{"", 0, false},
+		{"MB", 0, false},
+		{"10 parsecs", 0, false},
+		{"-1KB", 0, false},
+		{"100EiB", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseBytes(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"1h30m", 90 * time.Minute, true},
+		{"90s", 90 * time.Second, true},
+		{"2d12h", 60 * time.Hour, true},
+		{"2 days", 48 * time.Hour, true},
+		{"1 hour 30 minutes", 90 * time.Minute, true},
+		{"1.5", 1500 * time.Millisecond, true},
+		{"-2m", -2 * time.Minute, true},
+		{"", 0, false},
+		{"  ", 0, false},
+		{"-", 0, false},
+		{"--5s", 0, false},
+		{"-+-5s", 0, false},
+		{"+-5", 0, false},
+		{"+5s", 5 * time.Second, true},
+		{"10000000000", 0, false},
+		{"300000w", 0, false},
+		{"5 fortnights", 0, false},
+		{"h", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestUnitStrings(t *testing.T) {
+	sizes := map[ByteSize]string{10e6: "10MB", 3 << 29: "1.5GiB", 1234567: "1234567B", 1 << 10: "1KiB"}
+	for b, want := range sizes {
+		if got := b.String(); got != want {
+			t.Errorf("ByteSize(%d) is %q, want %q", uint64(b), got, want)
+		}
+	}
+	durations := map[time.Duration]string{90 * time.Minute: "1h30m", 48 * time.Hour: "2d", 90 * time.Second: "1m30s", 1500 * time.Millisecond: "1.5s"}
+	for d, want := range durations {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%v) is %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestUnitPlaceholders(t *testing.T) {
+	p := MustCompile("limit ${mem:Bytes} for ${ttl:Duration}")
+	in, err := p.MatchString("limit 64 KiB for 2 days")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.Get("mem").Value != ByteSize(64<<10) || in.Get("ttl").Value != 48*time.Hour {
+		t.Errorf("got %v", in.All())
+	}
+	line, err := p.Render(in.All())
+	if err != nil || line != "limit 64KiB for 2d" {
+		t.Errorf("Render gave %q, %v", line, err)
+	}
+}
+
+func TestUnitWordsOnlyJoinForUnits(t *testing.T) {
+	tests := []struct {
+		format, line string
+		want         map[string]any
+	}{
+		{"retain ${n:Int} days max ${size:Bytes}", "retain 7 days max 10MB", map[string]any{"n": 7, "size": ByteSize(10e6)}},
+		{"retain ${n:Int} days max ${size:Bytes}", "retain 7 days max 10 MB", map[string]any{"n": 7, "size": ByteSize(10e6)}},
+		{"wait ${d:Duration}", "wait --d 2 days", map[string]any{"d": 48 * time.Hour}},
+	}
+	for _, tt := range tests {
+		in, err := MustCompile(tt.format, WithFlags()).MatchString(tt.line)
+		if err != nil || !reflect.DeepEqual(in.All(), tt.want) {
+			t.Errorf("%q: got %v, %v, want %v", tt.line, in.All(), err, tt.want)
+		}
+	}
+}